@@ -0,0 +1,58 @@
+package apperror
+
+import (
+	// nolint:staticcheck
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is an application error carrying the GRPC code and details it must be reported with.
+// The grpcerror interceptor recognizes it, even wrapped, and returns its status to the client.
+type Error struct {
+	code    codes.Code
+	msg     string
+	details []proto.Message
+	cause   error
+}
+
+// New returns an Error with the given code, message and details.
+func New(code codes.Code, msg string, details ...proto.Message) *Error {
+	return &Error{code: code, msg: msg, details: details}
+}
+
+// Wrap returns an Error with the given code and message, caused by err.
+func Wrap(err error, code codes.Code, msg string, details ...proto.Message) *Error {
+	return &Error{code: code, msg: msg, details: details, cause: err}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.msg + ": " + e.cause.Error()
+	}
+	return e.msg
+}
+
+// Code returns the GRPC code of e.
+func (e *Error) Code() codes.Code {
+	return e.code
+}
+
+// GRPCStatus returns the status of e, used by status.FromError and status.Code.
+// Details which cannot be attached are dropped.
+func (e *Error) GRPCStatus() *status.Status {
+	stt := status.New(e.code, e.msg)
+	if len(e.details) == 0 {
+		return stt
+	}
+	if s, err := stt.WithDetails(e.details...); err == nil {
+		return s
+	}
+	return stt
+}
+
+// Unwrap returns the cause of e, nil when it was built with New.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,40 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestError(t *testing.T) {
+	err := New(codes.NotFound, "user not found", wrapperspb.String("user_id"))
+	if err.Error() != "user not found" || err.Code() != codes.NotFound || err.Unwrap() != nil {
+		t.Fatalf("New = (%q, %s, %v)", err.Error(), err.Code(), err.Unwrap())
+	}
+	stt, ok := status.FromError(err)
+	if !ok || stt.Code() != codes.NotFound || stt.Message() != "user not found" {
+		t.Fatalf("status = %v, want NotFound user not found", stt)
+	}
+	if details := stt.Details(); len(details) != 1 || details[0].(*wrapperspb.StringValue).GetValue() != "user_id" {
+		t.Fatalf("details = %v, want user_id", details)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := Wrap(cause, codes.Unavailable, "database unavailable")
+	if err.Error() != "database unavailable: connection reset" {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Fatal("cause is not unwrapped")
+	}
+	var appErr *Error
+	if !errors.As(fmt.Errorf("load user: %w", err), &appErr) || appErr.Code() != codes.Unavailable {
+		t.Fatal("wrapped Error not found by errors.As")
+	}
+}
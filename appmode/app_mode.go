@@ -1,5 +1,10 @@
 package appmode
 
+import (
+	"fmt"
+	"strings"
+)
+
 //go:generate go run github.com/dmarkham/enumer -type=AppMode -json
 type AppMode int
 
@@ -15,3 +20,31 @@ func GetAppMode(appMode string) AppMode {
 	}
 	return mode
 }
+
+// All returns every defined mode, in declaration order.
+func All() []AppMode {
+	modes := make([]AppMode, len(_AppModeValues))
+	copy(modes, _AppModeValues)
+	return modes
+}
+
+// IsValid reports whether m is one of the defined modes.
+func (m AppMode) IsValid() bool {
+	return m.IsAAppMode()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for AppMode
+func (i AppMode) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for AppMode.
+// Unlike GetAppMode, unknown values are rejected.
+func (i *AppMode) UnmarshalText(text []byte) error {
+	mode, err := AppModeString(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid app mode %q, valid modes are: %s", text, strings.Join(AppModeStrings(), ", "))
+	}
+	*i = mode
+	return nil
+}
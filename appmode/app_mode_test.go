@@ -0,0 +1,63 @@
+package appmode
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+type config struct {
+	Mode AppMode `yaml:"mode"`
+}
+
+func TestAppModeYAMLRoundTrip(t *testing.T) {
+	for _, mode := range All() {
+		out, err := yaml.Marshal(config{Mode: mode})
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", mode, err)
+		}
+		if want := "mode: " + mode.String() + "\n"; string(out) != want {
+			t.Fatalf("Marshal(%s) = %q, want %q", mode, out, want)
+		}
+		var got config
+		if err := yaml.Unmarshal(out, &got); err != nil {
+			t.Fatalf("Unmarshal(%q): %v", out, err)
+		}
+		if got.Mode != mode {
+			t.Fatalf("round trip of %s = %s", mode, got.Mode)
+		}
+	}
+}
+
+func TestAppModeUnmarshalTextRejectsUnknown(t *testing.T) {
+	var c config
+	err := yaml.Unmarshal([]byte("mode: staging\n"), &c)
+	if err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+	for _, mode := range AppModeStrings() {
+		if !strings.Contains(err.Error(), mode) {
+			t.Errorf("error %q does not name the valid mode %s", err, mode)
+		}
+	}
+}
+
+func TestAllAndIsValid(t *testing.T) {
+	modes := All()
+	if len(modes) != 2 {
+		t.Fatalf("All() = %v, want the 2 defined modes", modes)
+	}
+	for _, mode := range modes {
+		if !mode.IsValid() {
+			t.Errorf("%s is not valid", mode)
+		}
+	}
+	if AppMode(99).IsValid() {
+		t.Error("AppMode(99) is valid")
+	}
+	modes[0] = AppMode(99)
+	if All()[0] == AppMode(99) {
+		t.Error("All returns the internal slice")
+	}
+}
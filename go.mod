@@ -5,18 +5,20 @@ go 1.19
 require (
 	github.com/golang/protobuf v1.5.2
 	github.com/sirupsen/logrus v1.9.0
+	go.opentelemetry.io/otel/trace v1.11.1
+	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.23.0
 	google.golang.org/grpc v1.50.0
+	google.golang.org/protobuf v1.27.1
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
+	go.opentelemetry.io/otel v1.11.1 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
-	go.uber.org/multierr v1.6.0 // indirect
 	golang.org/x/net v0.0.0-20201021035429-f5854403a974 // indirect
 	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
 	golang.org/x/text v0.3.3 // indirect
 	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
-	google.golang.org/protobuf v1.27.1 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -0,0 +1,29 @@
+package client
+
+import "google.golang.org/grpc"
+
+// WithMaxRecvMsgSize returns a DialOption which sets the maximum size in bytes of a message the client can receive.
+// GRPC defaults to 4MB. Raising it allows large responses at the cost of memory, every message being fully
+// buffered before being decoded; prefer streaming for payloads of unbounded size.
+func WithMaxRecvMsgSize(n int) grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(n))
+}
+
+// WithMaxSendMsgSize returns a DialOption which sets the maximum size in bytes of a message the client can send.
+// The server enforces its own receive limit (4MB by default), which must be raised as well.
+func WithMaxSendMsgSize(n int) grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(n))
+}
+
+// WithUserAgent returns a DialOption which identifies the client as "name/version" in the user-agent metadata.
+// With NewClientWithOptions, pass it in ClientOptions.DialOptions or set ClientOptions.UserAgent.
+func WithUserAgent(name, version string) grpc.DialOption {
+	return grpc.WithUserAgent(name + "/" + version)
+}
+
+// WithWaitForReady returns a DialOption which makes calls wait for the connection to be ready instead of failing
+// fast with `Unavailable` while it is connecting or in transient failure. This smooths short connectivity losses,
+// at the cost of latency: a call blocks until the connection recovers or its deadline expires, so set deadlines.
+func WithWaitForReady() grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.WaitForReady(true))
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+)
+
+// sizedService returns replies of the requested size.
+func sizedService() *testService {
+	return &testService{unary: func(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+		return &testpb.SimpleResponse{Payload: &testpb.Payload{Body: make([]byte, req.GetResponseSize())}}, nil
+	}}
+}
+
+func TestWithMaxRecvMsgSize(t *testing.T) {
+	const size = 5 << 20
+	serverOpts := []grpc.ServerOption{grpc.MaxSendMsgSize(size + 1024)}
+	req := &testpb.SimpleRequest{ResponseSize: size}
+
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, startBufconnServer(t, sizedService(), serverOpts...)...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	if _, err := client.UnaryCall(context.Background(), req); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("UnaryCall with the default limit = %v, want ResourceExhausted", err)
+	}
+
+	opts := append(startBufconnServer(t, sizedService(), serverOpts...), WithMaxRecvMsgSize(size+1024))
+	client, closeFunc, err = NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	res, err := client.UnaryCall(context.Background(), req)
+	if err != nil {
+		t.Fatalf("UnaryCall with a raised limit: %v", err)
+	}
+	if n := len(res.GetPayload().GetBody()); n != size {
+		t.Fatalf("got %d bytes, want %d", n, size)
+	}
+}
+
+func TestWithMaxSendMsgSize(t *testing.T) {
+	opts := append(startBufconnServer(t, &testService{}), WithMaxSendMsgSize(1024))
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: make([]byte, 512)}}); err != nil {
+		t.Fatalf("UnaryCall under the limit: %v", err)
+	}
+	_, err = client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: make([]byte, 2048)}})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("UnaryCall over the limit = %v, want ResourceExhausted", err)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	svc, mds := incomingMetadata()
+	opts := append(startBufconnServer(t, svc), WithUserAgent("billing", "1.2.3"))
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+	// GRPC appends its own name and version.
+	if ua := (<-mds).Get("user-agent"); len(ua) != 1 || !strings.HasPrefix(ua[0], "billing/1.2.3 grpc-go/") {
+		t.Fatalf("user-agent = %v, want billing/1.2.3 first", ua)
+	}
+}
+
+func TestClientOptionsUserAgent(t *testing.T) {
+	svc, mds := incomingMetadata()
+	client, closeFunc, err := NewClientWithOptions("bufnet", testpb.NewTestServiceClient, ClientOptions{
+		UserAgent:   "reporting/2.0",
+		DialOptions: startBufconnServer(t, svc)[:1],
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	defer closeFunc()
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+	if ua := (<-mds).Get("user-agent"); len(ua) != 1 || !strings.HasPrefix(ua[0], "reporting/2.0 ") {
+		t.Fatalf("user-agent = %v, want reporting/2.0 first", ua)
+	}
+}
+
+func TestWithWaitForReady(t *testing.T) {
+	addr := freeAddr(t)
+	fastBackoff := grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1.6, MaxDelay: 50 * time.Millisecond}})
+
+	failFast, closeFailFast, err := NewClient(addr, testpb.NewTestServiceClient, grpc.WithTransportCredentials(insecure.NewCredentials()), fastBackoff)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFailFast()
+	waiting, closeWaiting, err := NewClient(addr, testpb.NewTestServiceClient, grpc.WithTransportCredentials(insecure.NewCredentials()), fastBackoff, WithWaitForReady())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeWaiting()
+
+	// The server is not started yet, a fail-fast call errors out.
+	if _, err := failFast.UnaryCall(context.Background(), &testpb.SimpleRequest{}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("fail-fast call error = %v, want code Unavailable", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := waiting.UnaryCall(ctx, &testpb.SimpleRequest{})
+		callErr <- err
+	}()
+	select {
+	case err := <-callErr:
+		t.Fatalf("wait-for-ready call returned %v before the server started", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	s := serveAt(t, addr)
+	defer s.Stop()
+	if err := <-callErr; err != nil {
+		t.Fatalf("wait-for-ready call: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package client
+
+import "google.golang.org/grpc"
+
+// ChainUnary returns a DialOption that installs the given unary client interceptors.
+// The first interceptor will be the outer most, while the last interceptor will be the inner most wrapper around the real call.
+func ChainUnary(interceptors ...grpc.UnaryClientInterceptor) grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(interceptors...)
+}
+
+// ChainStream returns a DialOption that installs the given stream client interceptors.
+// The first interceptor will be the outer most, while the last interceptor will be the inner most wrapper around the real call.
+func ChainStream(interceptors ...grpc.StreamClientInterceptor) grpc.DialOption {
+	return grpc.WithChainStreamInterceptor(interceptors...)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+)
+
+func TestChainUnaryAndStreamOrder(t *testing.T) {
+	rec := middlewaretest.RecordOrder()
+	opts := append(startBufconnServer(t, &testService{}),
+		ChainUnary(rec.UnaryClientInterceptor("a"), rec.UnaryClientInterceptor("b")),
+		ChainStream(rec.StreamClientInterceptor("c"), rec.StreamClientInterceptor("d")),
+	)
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+	if got, want := rec.Order(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unary order = %v, want %v", got, want)
+	}
+
+	rec.Reset()
+	stream, err := client.StreamingOutputCall(context.Background(), &testpb.StreamingOutputCallRequest{})
+	if err != nil {
+		t.Fatalf("StreamingOutputCall: %v", err)
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Fatal("Recv: want end of stream")
+	}
+	if got, want := rec.Order(), []string{"c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("stream order = %v, want %v", got, want)
+	}
+}
+
+func TestNewClientWithOptionsInterceptorOrder(t *testing.T) {
+	rec := middlewaretest.RecordOrder()
+	client, closeFunc, err := NewClientWithOptions("bufnet", testpb.NewTestServiceClient, ClientOptions{
+		UnaryInterceptors: []grpc.UnaryClientInterceptor{
+			rec.UnaryClientInterceptor("tracing"),
+			rec.UnaryClientInterceptor("retry"),
+			rec.UnaryClientInterceptor("logging"),
+		},
+		// Only the dialer, the credentials are built from the options.
+		DialOptions: startBufconnServer(t, &testService{})[:1],
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	defer closeFunc()
+
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+	if got, want := rec.Order(), []string{"tracing", "retry", "logging"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+}
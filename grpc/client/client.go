@@ -7,6 +7,9 @@ import (
 
 // NewClient will return 3 params is GRPCClient instance, CloseFunc, error.
 // T must be particular GRPC Service Client interface. Like : ExampleServiceClient, HealthServiceClient...
+// serverAddr is a GRPC target, e.g. "localhost:10443", "dns:///example.com:443" or, for unix domain sockets,
+// "unix:///absolute/path/to.sock" and "unix:relative/path/to.sock". The unix scheme is resolved by GRPC itself,
+// so no custom dialer is needed.
 // Example:
 //
 // serverAddr := "localhost:10443"
@@ -15,14 +18,40 @@ import (
 //		 return examplev1.NewExampleServiceClient(conn)
 //	})
 func NewClient[T any](serverAddr string, newClientFunc func(conn grpc.ClientConnInterface) T, opts ...grpc.DialOption) (T, func() error, error) {
-	if len(opts) == 0 {
-		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	}
 	var client T
-	conn, err := grpc.Dial(serverAddr, opts...)
+	conn, err := NewClientConn(serverAddr, opts...)
 	if err != nil {
 		return client, nil, err
 	}
 	client = newClientFunc(conn)
 	return client, conn.Close, err
 }
+
+// NewClientConn dials serverAddr and returns the connection, so that several typed clients can share it.
+// Like NewClient, an insecure connection is used when no option is given. The caller must close the connection.
+// Example:
+//
+//	conn, err := NewClientConn(serverAddr)
+//	exampleClient := examplev1.NewExampleServiceClient(conn)
+//	healthClient := healthpb.NewHealthClient(conn)
+func NewClientConn(serverAddr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if len(opts) == 0 {
+		opts = defaultDialOptions()
+	}
+	return grpc.Dial(serverAddr, opts...)
+}
+
+func defaultDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+}
+
+// NewClientRaw is like NewClient but returns the underlying connection instead of its close func,
+// e.g. to watch its state with WatchState. The caller must close the connection.
+func NewClientRaw[T any](serverAddr string, newClientFunc func(conn grpc.ClientConnInterface) T, opts ...grpc.DialOption) (T, *grpc.ClientConn, error) {
+	var client T
+	conn, err := NewClientConn(serverAddr, opts...)
+	if err != nil {
+		return client, nil, err
+	}
+	return newClientFunc(conn), conn, nil
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+// testService is the test service of GRPC, its unary calls run unary when set and echo the payload otherwise.
+type testService struct {
+	testpb.UnimplementedTestServiceServer
+	unary func(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error)
+}
+
+func (s *testService) UnaryCall(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	if s.unary != nil {
+		return s.unary(ctx, req)
+	}
+	return &testpb.SimpleResponse{Payload: req.GetPayload()}, nil
+}
+
+func (s *testService) StreamingOutputCall(req *testpb.StreamingOutputCallRequest, stream testpb.TestService_StreamingOutputCallServer) error {
+	for _, p := range req.GetResponseParameters() {
+		if err := stream.Send(&testpb.StreamingOutputCallResponse{Payload: &testpb.Payload{Body: make([]byte, p.GetSize())}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startBufconnServer serves svc over an in-memory listener and returns the dial options connecting to it.
+func startBufconnServer(t *testing.T, svc testpb.TestServiceServer, opts ...grpc.ServerOption) []grpc.DialOption {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(opts...)
+	testpb.RegisterTestServiceServer(s, svc)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+}
+
+// incomingMetadata returns a service recording the metadata of its unary calls in the returned channel.
+func incomingMetadata() (*testService, chan metadata.MD) {
+	mds := make(chan metadata.MD, 16)
+	return &testService{unary: func(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		mds <- md
+		return &testpb.SimpleResponse{}, nil
+	}}, mds
+}
+
+func TestNewClient(t *testing.T) {
+	opts := startBufconnServer(t, &testService{})
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	res, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: []byte("hi")}})
+	if err != nil || string(res.GetPayload().GetBody()) != "hi" {
+		t.Fatalf("UnaryCall = (%v, %v)", res, err)
+	}
+}
+
+func TestNewClientConnShared(t *testing.T) {
+	opts := startBufconnServer(t, &testService{})
+	conn, err := NewClientConn("bufnet", opts...)
+	if err != nil {
+		t.Fatalf("NewClientConn: %v", err)
+	}
+	defer conn.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := testpb.NewTestServiceClient(conn).UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	// A client of another service reaches the same server over the same connection.
+	_, err = testpb.NewUnimplementedServiceClient(conn).UnimplementedCall(context.Background(), &testpb.Empty{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("UnimplementedCall error = %v, want code Unimplemented", err)
+	}
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Fatalf("shared connection state = %v, want READY", state)
+	}
+}
+
+func TestNewClientUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grpc.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := grpc.NewServer()
+	testpb.RegisterTestServiceServer(s, &testService{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	client, closeFunc, err := NewClient("unix://"+path, testpb.NewTestServiceClient)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	res, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: []byte("unix")}})
+	if err != nil || string(res.GetPayload().GetBody()) != "unix" {
+		t.Fatalf("UnaryCall = (%v, %v)", res, err)
+	}
+}
@@ -0,0 +1,13 @@
+package client
+
+import (
+	"google.golang.org/grpc"
+	// Register the gzip compressor.
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// WithGzip returns a DialOption which compresses every call with gzip.
+// The server must support gzip too, importing google.golang.org/grpc/encoding/gzip registers it on both sides.
+func WithGzip() grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name))
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/stats"
+)
+
+// compressionRecorder is a server stats handler recording the compression of the incoming calls.
+type compressionRecorder chan string
+
+func (r compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if h, ok := s.(*stats.InHeader); ok {
+		r <- h.Compression
+	}
+}
+
+func (r compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestWithGzip(t *testing.T) {
+	if encoding.GetCompressor(gzip.Name) == nil {
+		t.Fatal("gzip compressor not registered")
+	}
+	compressions := make(compressionRecorder, 1)
+	opts := append(startBufconnServer(t, &testService{}, grpc.StatsHandler(compressions)), WithGzip())
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+
+	body := bytes.Repeat([]byte("compressible "), 1<<16)
+	res, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: body}})
+	if err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+	if !bytes.Equal(res.GetPayload().GetBody(), body) {
+		t.Fatal("echoed payload differs")
+	}
+	if got := <-compressions; got != gzip.Name {
+		t.Fatalf("request compression = %q, want %s", got, gzip.Name)
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+)
+
+// ErrTLSRequired is returned by CredentialsForMode in production when no TLS config is given.
+var ErrTLSRequired = errors.New("TLS config is required in production")
+
+// CredentialsForMode returns a DialOption with the transport credentials of mode: plaintext in development,
+// whatever tlsCfg is, and TLS built from tlsCfg in production, failing with ErrTLSRequired when it is nil.
+func CredentialsForMode(mode appmode.AppMode, tlsCfg *TLSConfig) (grpc.DialOption, error) {
+	switch mode {
+	case appmode.Development:
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	case appmode.Production:
+		if tlsCfg == nil {
+			return nil, ErrTLSRequired
+		}
+		creds, err := tlsCfg.credentials()
+		if err != nil {
+			return nil, err
+		}
+		return grpc.WithTransportCredentials(creds), nil
+	default:
+		return nil, fmt.Errorf("unknown app mode %d", mode)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+)
+
+// selfSignedCert returns a certificate for localhost and the path of its PEM file, usable as a CA file.
+func selfSignedCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, path
+}
+
+// callWith makes a call with creds to a server started with serverOpts.
+func callWith(t *testing.T, creds grpc.DialOption, serverOpts ...grpc.ServerOption) error {
+	t.Helper()
+	dialer := startBufconnServer(t, &testService{}, serverOpts...)[:1]
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, append(dialer, creds)...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.UnaryCall(ctx, &testpb.SimpleRequest{})
+	return err
+}
+
+func TestCredentialsForModeDevelopment(t *testing.T) {
+	// The TLS config is ignored in development.
+	creds, err := CredentialsForMode(appmode.Development, &TLSConfig{CAFile: "missing.pem"})
+	if err != nil {
+		t.Fatalf("CredentialsForMode: %v", err)
+	}
+	if err := callWith(t, creds); err != nil {
+		t.Fatalf("plaintext call: %v", err)
+	}
+}
+
+func TestCredentialsForModeProduction(t *testing.T) {
+	cert, caFile := selfSignedCert(t)
+	creds, err := CredentialsForMode(appmode.Production, &TLSConfig{CAFile: caFile, ServerName: "localhost"})
+	if err != nil {
+		t.Fatalf("CredentialsForMode: %v", err)
+	}
+	if err := callWith(t, creds, grpc.Creds(credentials.NewServerTLSFromCert(&cert))); err != nil {
+		t.Fatalf("TLS call: %v", err)
+	}
+}
+
+func TestCredentialsForModeErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		mode    appmode.AppMode
+		tlsCfg  *TLSConfig
+		wantErr error
+	}{
+		"production without TLS": {mode: appmode.Production, wantErr: ErrTLSRequired},
+		"missing CA file":        {mode: appmode.Production, tlsCfg: &TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, wantErr: os.ErrNotExist},
+		"unknown mode":           {mode: appmode.AppMode(42), tlsCfg: &TLSConfig{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			creds, err := CredentialsForMode(tc.mode, tc.tlsCfg)
+			if err == nil || creds != nil {
+				t.Fatalf("CredentialsForMode = (%v, %v), want an error", creds, err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// dialAttemptTimeout bounds every attempt of NewClientWithDialRetry, which otherwise fails as soon as the
+// connection is refused.
+const dialAttemptTimeout = 5 * time.Second
+
+// NewClientWithDialRetry is NewClientWithDialRetryContext with a background context.
+func NewClientWithDialRetry[T any](serverAddr string, attempts int, backoff time.Duration, newClientFunc func(conn grpc.ClientConnInterface) T, opts ...grpc.DialOption) (T, func() error, error) {
+	return NewClientWithDialRetryContext(context.Background(), serverAddr, attempts, backoff, newClientFunc, opts...)
+}
+
+// NewClientWithDialRetryContext is like NewClient but dials in blocking mode, so that the returned client is
+// connected. An attempt fails when the connection is refused or not ready within 5s, the next one starts after
+// waiting backoff, which doubles after every failed attempt. The last dial error is returned once attempts are
+// exhausted, the error of ctx if it is done first.
+func NewClientWithDialRetryContext[T any](ctx context.Context, serverAddr string, attempts int, backoff time.Duration, newClientFunc func(conn grpc.ClientConnInterface) T, opts ...grpc.DialOption) (T, func() error, error) {
+	if len(opts) == 0 {
+		opts = defaultDialOptions()
+	}
+	// Copied so that the backing array of the caller is never written.
+	opts = append(append(make([]grpc.DialOption, 0, len(opts)+2), opts...), grpc.WithBlock(), grpc.FailOnNonTempDialError(true))
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var client T
+	for i := 0; ; i++ {
+		conn, err := dialWithTimeout(ctx, serverAddr, dialAttemptTimeout, opts...)
+		if err == nil {
+			return newClientFunc(conn), conn.Close, nil
+		}
+		if i == attempts-1 {
+			return client, nil, err
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return client, nil, err
+		}
+		backoff *= 2
+	}
+}
+
+func dialWithTimeout(ctx context.Context, serverAddr string, timeout time.Duration, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return grpc.DialContext(ctx, serverAddr, opts...)
+}
+
+// sleepContext waits for d, it returns the error of ctx if it is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
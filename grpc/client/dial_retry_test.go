@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+)
+
+// freeAddr returns a local address nothing listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	_ = lis.Close()
+	return addr
+}
+
+func TestNewClientWithDialRetryWaitsForServer(t *testing.T) {
+	addr := freeAddr(t)
+	s := grpc.NewServer()
+	testpb.RegisterTestServiceServer(s, &testService{})
+	defer s.Stop()
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = s.Serve(lis)
+	}()
+
+	client, closeFunc, err := NewClientWithDialRetry(addr, 6, 50*time.Millisecond, testpb.NewTestServiceClient)
+	if err != nil {
+		t.Fatalf("NewClientWithDialRetry: %v", err)
+	}
+	defer closeFunc()
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+}
+
+// recordingDialer dials addr, whatever the target, and records the time of every attempt.
+type recordingDialer struct {
+	addr  string
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (d *recordingDialer) dial(ctx context.Context, _ string) (net.Conn, error) {
+	d.mu.Lock()
+	d.times = append(d.times, time.Now())
+	d.mu.Unlock()
+	return (&net.Dialer{}).DialContext(ctx, "tcp", d.addr)
+}
+
+func (d *recordingDialer) attempts() []time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]time.Time(nil), d.times...)
+}
+
+func TestNewClientWithDialRetryBacksOff(t *testing.T) {
+	dialer := &recordingDialer{addr: freeAddr(t)}
+	start := time.Now()
+	_, closeFunc, err := NewClientWithDialRetry("refused", 3, 50*time.Millisecond, testpb.NewTestServiceClient,
+		grpc.WithContextDialer(dialer.dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err == nil {
+		closeFunc()
+		t.Fatal("NewClientWithDialRetry succeeded without server")
+	}
+	times := dialer.attempts()
+	if len(times) != 3 {
+		t.Fatalf("dialed %d times, want 3", len(times))
+	}
+	// A refused attempt fails right away, the attempts are then 50ms and 100ms apart.
+	for i, want := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond} {
+		if gap := times[i+1].Sub(times[i]); gap < want {
+			t.Errorf("attempt %d started %v after the previous one, want at least %v", i+2, gap, want)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("gave up after %v, a refused attempt must not wait for its timeout", elapsed)
+	}
+}
+
+func TestNewClientWithDialRetryContextCanceled(t *testing.T) {
+	dialer := &recordingDialer{addr: freeAddr(t)}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, closeFunc, err := NewClientWithDialRetryContext(ctx, "refused", 5, time.Hour, testpb.NewTestServiceClient,
+		grpc.WithContextDialer(dialer.dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err == nil {
+		closeFunc()
+		t.Fatal("NewClientWithDialRetryContext succeeded without server")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want the error of the context", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("returned after %v, want once the context is done", elapsed)
+	}
+	if n := len(dialer.attempts()); n != 1 {
+		t.Fatalf("dialed %d times, want 1", n)
+	}
+}
+
+func TestNewClientWithDialRetryKeepsCallerOptions(t *testing.T) {
+	opts := make([]grpc.DialOption, 1, 2)
+	opts[0] = grpc.WithTransportCredentials(insecure.NewCredentials())
+	sentinel := grpc.WithUserAgent("sentinel")
+	backing := opts[:2]
+	backing[1] = sentinel
+
+	_, closeFunc, err := NewClientWithDialRetry(freeAddr(t), 1, 10*time.Millisecond, testpb.NewTestServiceClient, opts...)
+	if err == nil {
+		closeFunc()
+	}
+	if backing[1] != sentinel {
+		t.Fatal("the backing array of the caller options was written")
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+)
+
+// WithDNSRefresh returns a DialOption which asks the DNS resolver to re-resolve the target every interval,
+// so that a connection follows the IP changes of a service without waiting for a connection failure.
+//
+// It only applies to targets with the dns scheme, e.g. "dns:///example.com:443": grpc.Dial passes targets
+// without scheme, like "example.com:443", as is to the system dialer. The GRPC resolver rate limits
+// re-resolution to once every 30 seconds, shorter intervals are accepted but not honored.
+//
+// Callers must also set the round_robin balancer, e.g. with RoundRobinServiceConfig, which a single DialOption
+// cannot do: with the default pick_first, every call goes to one of the resolved addresses and the calls are
+// never spread across the refreshed ones. ClientOptions.DNSRefresh sets both.
+//
+//	conn, err := grpc.Dial("dns:///example.com:443",
+//		client.WithDNSRefresh(time.Minute),
+//		grpc.WithDefaultServiceConfig(client.RoundRobinServiceConfig),
+//	)
+func WithDNSRefresh(interval time.Duration) grpc.DialOption {
+	return grpc.WithResolvers(&refreshBuilder{inner: resolver.Get("dns"), interval: interval})
+}
+
+// RoundRobinServiceConfig is the service config spreading calls over every resolved address, see WithDNSRefresh.
+// It replaces the default service config, merge it with any other setting such as a retry policy.
+const RoundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
+// refreshBuilder builds the resolvers of inner, calling their ResolveNow every interval.
+type refreshBuilder struct {
+	inner    resolver.Builder
+	interval time.Duration
+}
+
+func (b *refreshBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	r, err := b.inner.Build(target, cc, opts)
+	if err != nil {
+		return nil, err
+	}
+	rr := &refreshResolver{Resolver: r, stop: make(chan struct{})}
+	go rr.refresh(b.interval)
+	return rr, nil
+}
+
+func (b *refreshBuilder) Scheme() string {
+	return b.inner.Scheme()
+}
+
+type refreshResolver struct {
+	resolver.Resolver
+	stop chan struct{}
+	once sync.Once
+}
+
+func (r *refreshResolver) refresh(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.ResolveNow(resolver.ResolveNowOptions{})
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *refreshResolver) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+	r.Resolver.Close()
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// namedServer serves the test service on a local port, answering every unary call with name.
+func namedServer(t *testing.T, name string) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	testpb.RegisterTestServiceServer(s, &testService{unary: func(context.Context, *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+		return &testpb.SimpleResponse{Payload: &testpb.Payload{Body: []byte(name)}}, nil
+	}})
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestDNSRefreshFollowsAddressChange(t *testing.T) {
+	before, after := namedServer(t, "before"), namedServer(t, "after")
+
+	// The manual resolver stands for DNS: every re-resolution answers the current address.
+	var (
+		mu      sync.Mutex
+		current = before
+	)
+	r := manual.NewBuilderWithScheme("refresh-test")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: before}}})
+	r.ResolveNowCallback = func(resolver.ResolveNowOptions) {
+		mu.Lock()
+		addr := current
+		mu.Unlock()
+		// Updated asynchronously, GRPC may call ResolveNow with its own locks held.
+		go r.UpdateState(resolver.State{Addresses: []resolver.Address{{Addr: addr}}})
+	}
+
+	conn, err := grpc.Dial(r.Scheme()+":///service",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(&refreshBuilder{inner: r, interval: 10 * time.Millisecond}),
+		grpc.WithDefaultServiceConfig(RoundRobinServiceConfig),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	client := testpb.NewTestServiceClient(conn)
+	reached := func() string {
+		t.Helper()
+		res, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}, grpc.WaitForReady(true))
+		if err != nil {
+			t.Fatalf("UnaryCall: %v", err)
+		}
+		return string(res.GetPayload().GetBody())
+	}
+
+	if got := reached(); got != "before" {
+		t.Fatalf("call reached %q, want before", got)
+	}
+	mu.Lock()
+	current = after
+	mu.Unlock()
+	// The connection to the old address is still healthy, only the periodic re-resolution moves the calls.
+	for deadline := time.Now().Add(5 * time.Second); reached() != "after"; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("calls still reach the old address")
+		}
+	}
+}
+
+func TestRoundRobinServiceConfigSpreadsCalls(t *testing.T) {
+	first, second := namedServer(t, "first"), namedServer(t, "second")
+	r := manual.NewBuilderWithScheme("round-robin-test")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: first}, {Addr: second}}})
+
+	conn, err := grpc.Dial(r.Scheme()+":///service",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(RoundRobinServiceConfig),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	client := testpb.NewTestServiceClient(conn)
+	reached := map[string]bool{}
+	for deadline := time.Now().Add(5 * time.Second); len(reached) < 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("calls reached %v, want both addresses", reached)
+		}
+		res, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}, grpc.WaitForReady(true))
+		if err != nil {
+			t.Fatalf("UnaryCall: %v", err)
+		}
+		reached[string(res.GetPayload().GetBody())] = true
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// WithMessageSizeLogging returns a unary client interceptor which logs the size in bytes of the request and of the reply.
+// Sizes of messages which are not protobuf messages are not logged.
+func WithMessageSizeLogging() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		fields := logger.Fields{"grpc.method": method}
+		if m, ok := req.(proto.Message); ok {
+			fields["grpc.request_size"] = proto.Size(m)
+		}
+		if m, ok := reply.(proto.Message); ok && err == nil {
+			fields["grpc.response_size"] = proto.Size(m)
+		}
+		logger.WithFields(fields).Info("message size")
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWithMessageSizeLogging(t *testing.T) {
+	opts := append(startBufconnServer(t, &testService{}), ChainUnary(WithMessageSizeLogging()))
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	logs.Entries(t)
+
+	req := &testpb.SimpleRequest{Payload: &testpb.Payload{Body: bytes.Repeat([]byte("x"), 300)}, FillUsername: true}
+	res, err := client.UnaryCall(context.Background(), req)
+	if err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+	es := logs.Entries(t)
+	if len(es) != 1 {
+		t.Fatalf("got %d entries, want 1", len(es))
+	}
+	if got, want := es[0]["grpc.request_size"], float64(proto.Size(req)); got != want {
+		t.Errorf("grpc.request_size = %v, want %v", got, want)
+	}
+	if got, want := es[0]["grpc.response_size"], float64(proto.Size(res)); got != want {
+		t.Errorf("grpc.response_size = %v, want %v", got, want)
+	}
+	if proto.Size(req) == proto.Size(res) {
+		t.Error("request and reply have the same size, the test cannot tell them apart")
+	}
+}
+
+func TestWithMessageSizeLoggingSkipsNonProtoMessages(t *testing.T) {
+	logs.Entries(t)
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return nil
+	}
+	if err := WithMessageSizeLogging()(context.Background(), "/test.Service/Call", "request", new(string), nil, invoker); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+	es := logs.Entries(t)
+	if len(es) != 1 {
+		t.Fatalf("got %d entries, want 1", len(es))
+	}
+	for _, k := range []string{"grpc.request_size", "grpc.response_size"} {
+		if v, ok := es[0][k]; ok {
+			t.Errorf("%s = %v, want absent", k, v)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithMetadata returns unary and stream client interceptors which add md to the outgoing metadata of every call,
+// e.g. a tenant id or an api key. Keys already set by the caller keep their values.
+// Example:
+//
+//	unary, stream := WithMetadata(map[string]string{"x-tenant-id": tenantID})
+//	conn, err := NewClientConn(serverAddr,
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithChainUnaryInterceptor(unary),
+//		grpc.WithChainStreamInterceptor(stream),
+//	)
+func WithMetadata(md map[string]string) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	// Metadata keys are case insensitive and stored in lower case.
+	pairs := make(map[string]string, len(md))
+	for k, v := range md {
+		pairs[strings.ToLower(k)] = v
+	}
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withMetadata(ctx, pairs), method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withMetadata(ctx, pairs), desc, cc, method, opts...)
+	}
+	return unary, stream
+}
+
+// withMetadata returns ctx with the pairs whose key is not already in its outgoing metadata.
+func withMetadata(ctx context.Context, pairs map[string]string) context.Context {
+	existing, _ := metadata.FromOutgoingContext(ctx)
+	kv := make([]string, 0, 2*len(pairs))
+	for k, v := range pairs {
+		if len(existing.Get(k)) > 0 {
+			continue
+		}
+		kv = append(kv, k, v)
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
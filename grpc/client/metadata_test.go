@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithMetadata(t *testing.T) {
+	svc, mds := incomingMetadata()
+	unary, stream := WithMetadata(map[string]string{"X-Tenant-Id": "acme", "x-api-key": "default-key"})
+	opts := append(startBufconnServer(t, svc), grpc.WithChainUnaryInterceptor(unary), grpc.WithChainStreamInterceptor(stream))
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		want map[string][]string
+	}{
+		"injected": {
+			ctx:  context.Background(),
+			want: map[string][]string{"x-tenant-id": {"acme"}, "x-api-key": {"default-key"}},
+		},
+		"caller value wins": {
+			ctx:  metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "caller-key"),
+			want: map[string][]string{"x-tenant-id": {"acme"}, "x-api-key": {"caller-key"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.UnaryCall(tc.ctx, &testpb.SimpleRequest{}); err != nil {
+				t.Fatalf("UnaryCall: %v", err)
+			}
+			md := <-mds
+			for k, want := range tc.want {
+				if got := md.Get(k); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %v, want %v", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMetadataStream(t *testing.T) {
+	_, stream := WithMetadata(map[string]string{"x-tenant-id": "acme"})
+	var md metadata.MD
+	streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1")
+	if _, err := stream(ctx, &grpc.StreamDesc{}, nil, "/test.Service/Stream", streamer); err != nil {
+		t.Fatalf("stream interceptor: %v", err)
+	}
+	want := metadata.Pairs("x-request-id", "req-1", "x-tenant-id", "acme")
+	if !reflect.DeepEqual(md, want) {
+		t.Fatalf("outgoing metadata = %v, want %v", md, want)
+	}
+}
@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/multierr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ClientOptions groups the common settings of a GRPC client in one place.
+// Zero value is a plaintext client without interceptors, keepalive or retry.
+// At most one of Insecure, TLS and TransportCredentials may be set.
+// nolint:revive
+type ClientOptions struct {
+	// Insecure explicitly requests a plaintext connection, which is also the default.
+	Insecure bool
+	// TLS secures the connection with certificates loaded from files.
+	TLS *TLSConfig
+	// UnaryInterceptors are chained in the declared order, the first one is the outer most.
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+	// StreamInterceptors are chained in the declared order, the first one is the outer most.
+	StreamInterceptors []grpc.StreamClientInterceptor
+	// TransportCredentials is used to secure the connection. Insecure credentials are used when nil.
+	TransportCredentials credentials.TransportCredentials
+	// Keepalive configures client side keepalive pings. Disabled when Keepalive.Time is 0.
+	Keepalive KeepaliveConfig
+	// Retry configures the retry policy applied to every method. Disabled when nil.
+	Retry *RetryPolicy
+	// MaxRecvMsgBytes is the maximum size of a received message. GRPC default (4MB) is used when 0.
+	MaxRecvMsgBytes int
+	// MaxSendMsgBytes is the maximum size of a sent message. GRPC default is used when 0.
+	MaxSendMsgBytes int
+	// UserAgent is prepended to the GRPC user agent. Not set when empty.
+	UserAgent string
+	// DNSRefresh re-resolves dns:/// targets every DNSRefresh and balances calls over the resolved addresses
+	// with round_robin, see WithDNSRefresh. Disabled when 0.
+	DNSRefresh time.Duration
+	// WarmupTimeout makes NewClientWithOptions wait up to this duration for the connection to be ready, see Warmup.
+	// The connection is lazy when 0.
+	WarmupTimeout time.Duration
+	// DialOptions are appended after the options built from the fields above.
+	DialOptions []grpc.DialOption
+}
+
+// KeepaliveConfig configures client side keepalive pings, see keepalive.ClientParameters.
+// The server must allow pings this frequent, or it closes the connection.
+type KeepaliveConfig struct {
+	// Time is the period of inactivity after which the client pings the server. Keepalive is disabled when 0.
+	Time time.Duration
+	// Timeout is how long the client waits for the ping ack before closing the connection. GRPC default (20s)
+	// is used when 0.
+	Timeout time.Duration
+	// PermitWithoutStream pings even without active calls.
+	PermitWithoutStream bool
+}
+
+// TLSConfig describes the certificates of a TLS connection.
+type TLSConfig struct {
+	// CAFile is the PEM encoded CA bundle used to verify the server. System roots are used when empty.
+	CAFile string
+	// CertFile and KeyFile are the PEM encoded client certificate and key, for mTLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// credentials loads the files and returns the matching transport credentials.
+func (c TLSConfig) credentials() (credentials.TransportCredentials, error) {
+	// nolint:gosec
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// RetryPolicy is the retry policy of GRPC service config.
+// See https://github.com/grpc/grpc/blob/master/doc/service_config.md for details.
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	BackoffMultiplier    float64
+	RetryableStatusCodes []codes.Code
+}
+
+// methodConfig renders the retry policy as a method config applied to all methods.
+func (p RetryPolicy) methodConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"name": []interface{}{map[string]interface{}{}},
+		"retryPolicy": map[string]interface{}{
+			"maxAttempts":          p.MaxAttempts,
+			"initialBackoff":       durationString(p.InitialBackoff),
+			"maxBackoff":           durationString(p.MaxBackoff),
+			"backoffMultiplier":    p.BackoffMultiplier,
+			"retryableStatusCodes": p.RetryableStatusCodes,
+		},
+	}
+}
+
+// serviceConfig renders the retry policy and the balancer as the default service config, empty when neither is set.
+func (o ClientOptions) serviceConfig() (string, error) {
+	cfg := map[string]interface{}{}
+	if o.Retry != nil {
+		cfg["methodConfig"] = []interface{}{o.Retry.methodConfig()}
+	}
+	if o.DNSRefresh > 0 {
+		cfg["loadBalancingConfig"] = []interface{}{map[string]interface{}{"round_robin": map[string]interface{}{}}}
+	}
+	if len(cfg) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// BuildDialOptions converts options to the list of grpc.DialOption which can be passed to NewClient.
+func (o ClientOptions) BuildDialOptions() ([]grpc.DialOption, error) {
+	creds, err := o.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if len(o.UnaryInterceptors) > 0 {
+		opts = append(opts, ChainUnary(o.UnaryInterceptors...))
+	}
+	if len(o.StreamInterceptors) > 0 {
+		opts = append(opts, ChainStream(o.StreamInterceptors...))
+	}
+	if o.Keepalive.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                o.Keepalive.Time,
+			Timeout:             o.Keepalive.Timeout,
+			PermitWithoutStream: o.Keepalive.PermitWithoutStream,
+		}))
+	}
+	sc, err := o.serviceConfig()
+	if err != nil {
+		return nil, fmt.Errorf("build service config: %w", err)
+	}
+	if sc != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(sc))
+	}
+	if o.DNSRefresh > 0 {
+		opts = append(opts, WithDNSRefresh(o.DNSRefresh))
+	}
+	if o.MaxRecvMsgBytes > 0 {
+		opts = append(opts, WithMaxRecvMsgSize(o.MaxRecvMsgBytes))
+	}
+	if o.MaxSendMsgBytes > 0 {
+		opts = append(opts, WithMaxSendMsgSize(o.MaxSendMsgBytes))
+	}
+	if o.UserAgent != "" {
+		opts = append(opts, grpc.WithUserAgent(o.UserAgent))
+	}
+	return append(opts, o.DialOptions...), nil
+}
+
+func (o ClientOptions) transportCredentials() (credentials.TransportCredentials, error) {
+	set := 0
+	for _, ok := range []bool{o.Insecure, o.TLS != nil, o.TransportCredentials != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errors.New("only one of Insecure, TLS and TransportCredentials can be set")
+	}
+	switch {
+	case o.TLS != nil:
+		return o.TLS.credentials()
+	case o.TransportCredentials != nil:
+		return o.TransportCredentials, nil
+	default:
+		return insecure.NewCredentials(), nil
+	}
+}
+
+// NewClientWithOptions is like NewClient but takes a ClientOptions instead of raw dial options.
+//
+//	client, closeFunc, err := NewClientWithOptions(serverAddr, examplev1.NewExampleServiceClient, ClientOptions{
+//		UnaryInterceptors: []grpc.UnaryClientInterceptor{tracing, logging},
+//	})
+func NewClientWithOptions[T any](serverAddr string, newClientFunc func(conn grpc.ClientConnInterface) T, options ClientOptions) (T, func() error, error) {
+	opts, err := options.BuildDialOptions()
+	if err != nil {
+		var client T
+		return client, nil, err
+	}
+	if options.WarmupTimeout <= 0 {
+		return NewClient(serverAddr, newClientFunc, opts...)
+	}
+	client, conn, err := NewClientRaw(serverAddr, newClientFunc, opts...)
+	if err != nil {
+		return client, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), options.WarmupTimeout)
+	defer cancel()
+	if err := Warmup(ctx, conn); err != nil {
+		var zero T
+		return zero, nil, multierr.Append(fmt.Errorf("warm up connection: %w", err), conn.Close())
+	}
+	return client, conn.Close, nil
+}
+
+func durationString(d time.Duration) string {
+	return fmt.Sprintf("%.9fs", d.Seconds())
+}
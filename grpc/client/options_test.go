@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+)
+
+func TestClientOptionsBuildDialOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		options ClientOptions
+		want    int
+	}{
+		"zero value":             {options: ClientOptions{}, want: 1},
+		"insecure":               {options: ClientOptions{Insecure: true}, want: 1},
+		"transport credentials":  {options: ClientOptions{TransportCredentials: insecure.NewCredentials()}, want: 1},
+		"unary interceptors":     {options: ClientOptions{UnaryInterceptors: []grpc.UnaryClientInterceptor{WithMessageSizeLogging()}}, want: 2},
+		"keepalive":              {options: ClientOptions{Keepalive: KeepaliveConfig{Time: time.Minute, Timeout: time.Second}}, want: 2},
+		"keepalive without time": {options: ClientOptions{Keepalive: KeepaliveConfig{Timeout: time.Second}}, want: 1},
+		"max recv":               {options: ClientOptions{MaxRecvMsgBytes: 1 << 20}, want: 2},
+		"max send":               {options: ClientOptions{MaxSendMsgBytes: 1 << 20}, want: 2},
+		"user agent":             {options: ClientOptions{UserAgent: "billing/1.0"}, want: 2},
+		"retry": {options: ClientOptions{Retry: &RetryPolicy{
+			MaxAttempts:          3,
+			InitialBackoff:       100 * time.Millisecond,
+			MaxBackoff:           time.Second,
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []codes.Code{codes.Unavailable},
+		}}, want: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts, err := tc.options.BuildDialOptions()
+			if err != nil {
+				t.Fatalf("BuildDialOptions: %v", err)
+			}
+			if len(opts) != tc.want {
+				t.Fatalf("got %d dial options, want %d", len(opts), tc.want)
+			}
+
+			// GRPC validates the options, e.g. the service config of the retry policy, and the call goes through.
+			options := tc.options
+			options.DialOptions = startBufconnServer(t, &testService{})[:1]
+			client, closeFunc, err := NewClientWithOptions("bufnet", testpb.NewTestServiceClient, options)
+			if err != nil {
+				t.Fatalf("NewClientWithOptions: %v", err)
+			}
+			defer closeFunc()
+			if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+				t.Fatalf("UnaryCall: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientOptionsCredentialsConflict(t *testing.T) {
+	for name, options := range map[string]ClientOptions{
+		"insecure and TLS":                   {Insecure: true, TLS: &TLSConfig{}},
+		"insecure and transport credentials": {Insecure: true, TransportCredentials: insecure.NewCredentials()},
+		"TLS and transport credentials":      {TLS: &TLSConfig{}, TransportCredentials: insecure.NewCredentials()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := options.BuildDialOptions(); err == nil {
+				t.Fatal("conflicting credentials accepted")
+			}
+		})
+	}
+}
+
+func TestClientOptionsTLSFiles(t *testing.T) {
+	if _, err := (ClientOptions{TLS: &TLSConfig{}}).BuildDialOptions(); err != nil {
+		t.Fatalf("TLS with system roots: %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := (ClientOptions{TLS: &TLSConfig{CAFile: filepath.Join(dir, "missing.pem")}}).BuildDialOptions(); err == nil {
+		t.Fatal("missing CA file accepted")
+	}
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("no certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (ClientOptions{TLS: &TLSConfig{CAFile: empty}}).BuildDialOptions(); err == nil {
+		t.Fatal("CA file without certificate accepted")
+	}
+	if _, err := (ClientOptions{TLS: &TLSConfig{CertFile: filepath.Join(dir, "cert.pem")}}).BuildDialOptions(); err == nil {
+		t.Fatal("missing client certificate accepted")
+	}
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPoolGracePeriod is the grace period used when PoolConfig.GracePeriod is not set.
+const DefaultPoolGracePeriod = 10 * time.Second
+
+var (
+	// ErrPoolClosed is returned by calls dispatched on a pool which is closing or closed.
+	ErrPoolClosed = status.Error(codes.Unavailable, "client pool is closed")
+	// ErrPoolDrainTimeout is returned by Close when in-flight calls did not finish within the grace period.
+	ErrPoolDrainTimeout = errors.New("client pool: in-flight calls did not finish within grace period")
+)
+
+// PoolConfig configures a ClientPool.
+type PoolConfig struct {
+	// Size is the number of connections, at least one connection is always opened.
+	Size int
+	// GracePeriod is the maximum time Close waits for in-flight calls before closing connections.
+	GracePeriod time.Duration
+}
+
+// ClientPool holds several connections to the same server and hands out clients in round robin.
+type ClientPool[T any] struct {
+	conns       []*grpc.ClientConn
+	clients     []T
+	next        uint32
+	gracePeriod time.Duration
+	// stopReconnect stops the goroutines reconnecting idle connections.
+	stopReconnect context.CancelFunc
+
+	mu       sync.RWMutex
+	closed   bool
+	inFlight sync.WaitGroup
+}
+
+// NewClientPool dials cfg.Size connections to serverAddr and wraps each of them with newClientFunc.
+//
+// Connections are established in the background and an unreachable server does not fail the pool:
+// failed connections are retried with the exponential backoff of GRPC, configurable with grpc.WithConnectParams,
+// while Get hands out the clients of the ready ones. Only invalid targets or options return an error.
+// Example:
+//
+//	pool, err := NewClientPool(serverAddr, PoolConfig{Size: 4}, examplev1.NewExampleServiceClient)
+//	defer pool.Close()
+//	res, err := pool.Get().Get(ctx, req)
+func NewClientPool[T any](serverAddr string, cfg PoolConfig, newClientFunc func(conn grpc.ClientConnInterface) T, opts ...grpc.DialOption) (*ClientPool[T], error) {
+	if len(opts) == 0 {
+		opts = defaultDialOptions()
+	}
+	size := cfg.Size
+	if size < 1 {
+		size = 1
+	}
+	gracePeriod := cfg.GracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultPoolGracePeriod
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &ClientPool[T]{
+		conns:         make([]*grpc.ClientConn, 0, size),
+		clients:       make([]T, 0, size),
+		gracePeriod:   gracePeriod,
+		stopReconnect: cancel,
+	}
+	// Copied so that the backing array of the caller is never written.
+	opts = append(append(make([]grpc.DialOption, 0, len(opts)+2), opts...),
+		grpc.WithChainUnaryInterceptor(p.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(p.streamInterceptor),
+	)
+	for i := 0; i < size; i++ {
+		conn, err := grpc.Dial(serverAddr, opts...)
+		if err != nil {
+			cancel()
+			return nil, multierr.Append(err, p.closeConns())
+		}
+		p.conns = append(p.conns, conn)
+		p.clients = append(p.clients, newClientFunc(conn))
+	}
+	for _, conn := range p.conns {
+		go keepConnecting(ctx, conn)
+	}
+	return p, nil
+}
+
+// keepConnecting connects conn whenever it goes idle, so that a connection which failed is retried
+// without waiting for a call, until ctx is done.
+func keepConnecting(ctx context.Context, conn *grpc.ClientConn) {
+	conn.Connect()
+	WatchState(ctx, conn, func(state connectivity.State) {
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+	})
+}
+
+// Get returns the next client in round robin, skipping the connections which are not ready.
+// When no connection is ready, the next client is returned anyway and its call waits or fails as usual.
+func (p *ClientPool[T]) Get() T {
+	n := int(atomic.AddUint32(&p.next, 1) - 1)
+	for i := 0; i < len(p.conns); i++ {
+		idx := (n + i) % len(p.conns)
+		if p.conns[idx].GetState() == connectivity.Ready {
+			return p.clients[idx]
+		}
+	}
+	return p.clients[n%len(p.clients)]
+}
+
+// Healthy returns the number of connections which are ready to serve calls.
+func (p *ClientPool[T]) Healthy() int {
+	healthy := 0
+	for _, conn := range p.conns {
+		if conn.GetState() == connectivity.Ready {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// Close stops dispatching new calls, waits up to the grace period for in-flight calls to finish
+// and then closes every connection. Errors are joined together.
+func (p *ClientPool[T]) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	var err error
+	drained := make(chan struct{})
+	go func() {
+		p.inFlight.Wait()
+		close(drained)
+	}()
+	timer := time.NewTimer(p.gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+		err = fmt.Errorf("%w after %s", ErrPoolDrainTimeout, p.gracePeriod)
+	}
+	p.stopReconnect()
+	return multierr.Append(err, p.closeConns())
+}
+
+func (p *ClientPool[T]) closeConns() error {
+	var err error
+	for _, conn := range p.conns {
+		err = multierr.Append(err, conn.Close())
+	}
+	return err
+}
+
+// acquire registers a new in-flight call, it fails once the pool is closing.
+func (p *ClientPool[T]) acquire() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	p.inFlight.Add(1)
+	return true
+}
+
+func (p *ClientPool[T]) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !p.acquire() {
+		return ErrPoolClosed
+	}
+	defer p.inFlight.Done()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (p *ClientPool[T]) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if !p.acquire() {
+		return nil, ErrPoolClosed
+	}
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		p.inFlight.Done()
+		return nil, err
+	}
+	// Stream context is cancelled once the stream finishes, whatever the reason.
+	go func() {
+		<-stream.Context().Done()
+		p.inFlight.Done()
+	}()
+	return stream, nil
+}
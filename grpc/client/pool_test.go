@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// slowRequest is the request slowService holds until release is closed.
+var slowRequest = &testpb.SimpleRequest{ResponseSize: 1}
+
+// slowService returns a service whose slowRequest calls signal started and then wait for release,
+// other calls return immediately.
+func slowService() (svc *testService, started, release chan struct{}) {
+	started, release = make(chan struct{}, 1), make(chan struct{})
+	return &testService{unary: func(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+		if req.GetResponseSize() == slowRequest.ResponseSize {
+			started <- struct{}{}
+			<-release
+		}
+		return &testpb.SimpleResponse{}, nil
+	}}, started, release
+}
+
+func TestClientPoolCloseDrainsInFlightCalls(t *testing.T) {
+	svc, started, release := slowService()
+	opts := startBufconnServer(t, svc)
+	pool, err := NewClientPool("bufnet", PoolConfig{Size: 2, GracePeriod: 5 * time.Second}, testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := pool.Get().UnaryCall(context.Background(), slowRequest)
+		callErr <- err
+	}()
+	<-started
+
+	closeErr := make(chan error, 1)
+	go func() { closeErr <- pool.Close() }()
+
+	// Close waits for the in-flight call, new calls are rejected meanwhile.
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err := pool.Get().UnaryCall(context.Background(), &testpb.SimpleRequest{})
+		if errors.Is(err, ErrPoolClosed) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("call during close returned %v, want ErrPoolClosed", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case err := <-closeErr:
+		t.Fatalf("Close returned %v before the in-flight call finished", err)
+	default:
+	}
+
+	close(release)
+	if err := <-callErr; err != nil {
+		t.Fatalf("in-flight call failed: %v", err)
+	}
+	if err := <-closeErr; err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestClientPoolCloseGracePeriod(t *testing.T) {
+	svc, started, release := slowService()
+	defer close(release)
+	opts := startBufconnServer(t, svc)
+	pool, err := NewClientPool("bufnet", PoolConfig{GracePeriod: 50 * time.Millisecond}, testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+	go func() { _, _ = pool.Get().UnaryCall(context.Background(), slowRequest) }()
+	<-started
+
+	if err := pool.Close(); !errors.Is(err, ErrPoolDrainTimeout) {
+		t.Fatalf("Close = %v, want ErrPoolDrainTimeout", err)
+	}
+}
+
+func TestNewClientPoolKeepsCallerOptions(t *testing.T) {
+	opts := make([]grpc.DialOption, 1, 3)
+	opts[0] = grpc.WithTransportCredentials(insecure.NewCredentials())
+	backing := opts[:3]
+	sentinel := grpc.WithUserAgent("sentinel")
+	backing[1], backing[2] = sentinel, sentinel
+
+	pool, err := NewClientPool("localhost:1", PoolConfig{}, testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+	_ = pool.Close()
+	if backing[1] != sentinel || backing[2] != sentinel {
+		t.Fatal("the backing array of the caller options was written")
+	}
+}
+
+// waitHealthy waits until n connections of pool are ready.
+func waitHealthy[T any](t *testing.T, pool *ClientPool[T], n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); pool.Healthy() != n; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d healthy connections, want %d", pool.Healthy(), n)
+		}
+	}
+}
+
+// countedConn decrements open once closed.
+type countedConn struct {
+	net.Conn
+	mu   *sync.Mutex
+	open *int
+	once sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() {
+		c.mu.Lock()
+		*c.open--
+		c.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+func TestClientPoolServesWhileConnecting(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	testpb.RegisterTestServiceServer(s, &testService{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	// Only two connections can be open until the endpoint of the third one is up, a closed one frees its slot
+	// so that a reconnecting connection is not refused.
+	var (
+		mu   sync.Mutex
+		open int
+		up   bool
+	)
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up && open == 2 {
+			return nil, errors.New("endpoint down")
+		}
+		conn, err := lis.DialContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		open++
+		return &countedConn{Conn: conn, open: &open, mu: &mu}, nil
+	}
+	pool, err := NewClientPool("bufnet", PoolConfig{Size: 3}, testpb.NewTestServiceClient,
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1.6, MaxDelay: 50 * time.Millisecond}}),
+	)
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+	defer pool.Close()
+
+	waitHealthy(t, pool, 2)
+	// Get skips the connection which is down, fail-fast calls all succeed.
+	for i := 0; i < 6; i++ {
+		if _, err := pool.Get().UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if n := pool.Healthy(); n != 2 {
+		t.Fatalf("%d healthy connections while the endpoint is down, want 2", n)
+	}
+
+	mu.Lock()
+	up = true
+	mu.Unlock()
+	waitHealthy(t, pool, 3)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// ListServices returns the fully-qualified names of the services exposed by the server at serverAddr,
+// using the server reflection API. It fails with a clear error when reflection is not registered on the server.
+func ListServices(ctx context.Context, serverAddr string, opts ...grpc.DialOption) ([]string, error) {
+	client, closeFunc, err := NewClient(serverAddr, rpb.NewServerReflectionClient, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFunc() // nolint:errcheck
+
+	stream, err := client.ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, reflectionError(serverAddr, err)
+	}
+	defer stream.CloseSend() // nolint:errcheck
+
+	if err := stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		return nil, reflectionError(serverAddr, err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, reflectionError(serverAddr, err)
+	}
+	if errRes := res.GetErrorResponse(); errRes != nil {
+		return nil, fmt.Errorf("list services of %s: %s", serverAddr, errRes.GetErrorMessage())
+	}
+
+	services := res.GetListServicesResponse().GetService()
+	names := make([]string, 0, len(services))
+	for _, s := range services {
+		names = append(names, s.GetName())
+	}
+	return names, nil
+}
+
+func reflectionError(serverAddr string, err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("server reflection is not enabled on %s: %w", serverAddr, err)
+	}
+	return fmt.Errorf("list services of %s: %w", serverAddr, err)
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestListServices(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	testpb.RegisterTestServiceServer(s, &testService{})
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	names, err := ListServices(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("ListServices: %v", err)
+	}
+	for _, name := range names {
+		if name == "grpc.testing.TestService" {
+			return
+		}
+	}
+	t.Fatalf("ListServices = %v, want grpc.testing.TestService", names)
+}
+
+func TestListServicesWithoutReflection(t *testing.T) {
+	_, err := ListServices(context.Background(), "bufnet", startBufconnServer(t, &testService{})...)
+	if err == nil || !strings.Contains(err.Error(), "reflection is not enabled") {
+		t.Fatalf("ListServices error = %v, want reflection is not enabled", err)
+	}
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// WithRequestIDPropagation returns unary and stream client interceptors which send the request id of the call
+// context in the `x-request-id` metadata, the header read by the requestid server interceptor, so that the
+// logs of both sides can be correlated.
+//
+// The id is the one assigned by the requestid interceptor when calling from a handler, otherwise the
+// "request_id" field set with logger.ContextWithFields. An id already set in the outgoing metadata is kept.
+func WithRequestIDPropagation() (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withRequestID(ctx), desc, cc, method, opts...)
+	}
+	return unary, stream
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	id, ok := requestid.FromContext(ctx)
+	if !ok {
+		id, _ = logger.FieldsFromContext(ctx)["request_id"].(string)
+	}
+	if id == "" {
+		return ctx
+	}
+	return withMetadata(ctx, map[string]string{requestid.MetadataKey: id})
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/logging"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+func TestRequestIDPropagationEndToEnd(t *testing.T) {
+	opts := startBufconnServer(t, &testService{}, grpc.ChainUnaryInterceptor(requestid.UnaryServerInterceptor(), logging.UnaryServerInterceptor()))
+	unary, stream := WithRequestIDPropagation()
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, append(opts, grpc.WithChainUnaryInterceptor(unary), grpc.WithChainStreamInterceptor(stream))...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	logs.Entries(t)
+
+	ctx := logger.ContextWithFields(context.Background(), logger.Fields{"request_id": "req-42"})
+	logger.WithContext(ctx).Info("calling billing")
+	if _, err := client.UnaryCall(ctx, &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+
+	seen := map[string]interface{}{}
+	for _, entry := range logs.Entries(t) {
+		seen[entry["msg"].(string)] = entry["request_id"]
+	}
+	want := map[string]interface{}{"calling billing": "req-42", "finished call": "req-42"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Fatalf("request ids by message %v, want %v", seen, want)
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	fromFields := logger.ContextWithFields(context.Background(), logger.Fields{"request_id": "from-fields"})
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		want []string
+	}{
+		"none":               {ctx: context.Background()},
+		"context fields":     {ctx: fromFields, want: []string{"from-fields"}},
+		"incoming handler":   {ctx: requestid.NewContext(fromFields, "from-handler"), want: []string{"from-handler"}},
+		"outgoing metadata":  {ctx: metadata.AppendToOutgoingContext(fromFields, requestid.MetadataKey, "explicit"), want: []string{"explicit"}},
+		"empty fields value": {ctx: logger.ContextWithFields(context.Background(), logger.Fields{"request_id": ""})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			md, _ := metadata.FromOutgoingContext(withRequestID(tc.ctx))
+			if got := md.Get(requestid.MetadataKey); !reflect.DeepEqual(got, tc.want) && len(got)+len(tc.want) > 0 {
+				t.Fatalf("x-request-id = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryBudgetMaxTokens caps the saved retries, so that a long healthy period does not allow a retry storm.
+const retryBudgetMaxTokens = 100
+
+// retryBudget allows retries in proportion to the successful calls: every successful call deposits ratio tokens
+// and every retry withdraws one, on top of minPerSec retries allowed every second.
+type retryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	minPerSec int
+	tokens    float64
+	second    time.Time
+	usedInSec int
+	now       func() time.Time
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > retryBudgetMaxTokens {
+		b.tokens = retryBudgetMaxTokens
+	}
+}
+
+// withdraw reports whether a retry is allowed, consuming the budget if so.
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now().Truncate(time.Second)
+	if !now.Equal(b.second) {
+		b.second, b.usedInSec = now, 0
+	}
+	if b.usedInSec < b.minPerSec {
+		b.usedInSec++
+		return true
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
+
+// DefaultRetryBudgetPolicy is the retry policy of WithRetryBudget when WithRetryPolicy is not given:
+// up to 3 attempts of the calls failing with `Unavailable`, spaced by 100ms then 200ms.
+var DefaultRetryBudgetPolicy = RetryPolicy{
+	MaxAttempts:          3,
+	InitialBackoff:       100 * time.Millisecond,
+	MaxBackoff:           time.Second,
+	BackoffMultiplier:    2,
+	RetryableStatusCodes: []codes.Code{codes.Unavailable},
+}
+
+// RetryBudgetOption configures WithRetryBudget.
+type RetryBudgetOption func(*retryBudgetOptions)
+
+type retryBudgetOptions struct {
+	policy RetryPolicy
+}
+
+// WithRetryPolicy sets the policy of the retries allowed by the budget, DefaultRetryBudgetPolicy by default.
+func WithRetryPolicy(policy RetryPolicy) RetryBudgetOption {
+	return func(o *retryBudgetOptions) {
+		o.policy = policy
+	}
+}
+
+// WithRetryBudget returns a unary client interceptor which retries the calls failing with one of the
+// RetryableStatusCodes of its policy, see WithRetryPolicy, as long as the retry budget allows it.
+//
+// The budget allows ratio retries per successful call, e.g. 0.1 allows one retry every ten successful calls,
+// plus minPerSec retries every second whatever the traffic. Failed calls do not refill it, so once it is
+// exhausted, during a backend brownout for instance, the error is returned without retrying and retries do not
+// amplify the outage. Attempts are spaced with the exponential backoff of the policy, randomized like GRPC does.
+// The budget is shared by every call of the interceptor.
+//
+// It replaces ClientOptions.Retry, which retries inside GRPC without any budget: do not set both.
+func WithRetryBudget(ratio float64, minPerSec int, opts ...RetryBudgetOption) grpc.UnaryClientInterceptor {
+	o := &retryBudgetOptions{policy: DefaultRetryBudgetPolicy}
+	for _, opt := range opts {
+		opt(o)
+	}
+	policy := o.policy
+	budget := &retryBudget{ratio: ratio, minPerSec: minPerSec, now: time.Now}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		backoff := policy.InitialBackoff
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		for attempt := 1; attempt < policy.MaxAttempts && policy.retryable(err); attempt++ {
+			if !budget.withdraw() {
+				return err
+			}
+			if backoff > 0 {
+				timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff))))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+			}
+			backoff = policy.nextBackoff(backoff)
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if err == nil {
+			budget.deposit()
+		}
+		return err
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := status.Code(err)
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) nextBackoff(backoff time.Duration) time.Duration {
+	next := time.Duration(float64(backoff) * p.BackoffMultiplier)
+	if p.MaxBackoff > 0 && next > p.MaxBackoff {
+		next = p.MaxBackoff
+	}
+	return next
+}
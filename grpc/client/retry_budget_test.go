@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var unavailablePolicy = RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []codes.Code{codes.Unavailable}}
+
+// countingInvoker fails the first failures attempts with Unavailable and counts every attempt.
+func countingInvoker(attempts *int, failures int) grpc.UnaryInvoker {
+	return func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		*attempts++
+		if *attempts <= failures {
+			return status.Error(codes.Unavailable, "backend down")
+		}
+		return nil
+	}
+}
+
+func TestRetryBudgetThrottles(t *testing.T) {
+	interceptor := WithRetryBudget(0.25, 0, WithRetryPolicy(unavailablePolicy))
+	// 40 successful calls deposit 10 retries.
+	attempts := 0
+	for i := 0; i < 40; i++ {
+		if err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, countingInvoker(&attempts, 0)); err != nil {
+			t.Fatalf("call %d error = %v", i, err)
+		}
+	}
+
+	// Consecutive failures use the budget up without refilling it: without budget, each call would be
+	// attempted 3 times.
+	attempts = 0
+	invoker := countingInvoker(&attempts, 1<<30)
+	for i := 0; i < 100; i++ {
+		if err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, invoker); status.Code(err) != codes.Unavailable {
+			t.Fatalf("call %d error = %v, want code Unavailable", i, err)
+		}
+	}
+	if retries := attempts - 100; retries != 10 {
+		t.Fatalf("%d retries for 100 failing calls, want the 10 of the budget", retries)
+	}
+	attempts = 0
+	for i := 0; i < 100; i++ {
+		_ = interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, invoker)
+	}
+	if retries := attempts - 100; retries != 0 {
+		t.Fatalf("%d retries once the budget is exhausted, want 0", retries)
+	}
+}
+
+func TestRetryBudgetDefaultPolicy(t *testing.T) {
+	interceptor := WithRetryBudget(0, 5)
+	attempts := 0
+	if err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, countingInvoker(&attempts, 1<<30)); status.Code(err) != codes.Unavailable {
+		t.Fatalf("error = %v, want code Unavailable", err)
+	}
+	if attempts != DefaultRetryBudgetPolicy.MaxAttempts {
+		t.Fatalf("%d attempts, want %d", attempts, DefaultRetryBudgetPolicy.MaxAttempts)
+	}
+}
+
+func TestRetryBudgetRetries(t *testing.T) {
+	interceptor := WithRetryBudget(0, 5, WithRetryPolicy(unavailablePolicy))
+	attempts := 0
+	if err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, countingInvoker(&attempts, 2)); err != nil {
+		t.Fatalf("error = %v, want the third attempt to succeed", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("%d attempts, want 3", attempts)
+	}
+
+	// Non retryable codes are returned as is.
+	attempts = 0
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		attempts++
+		return status.Error(codes.InvalidArgument, "bad request")
+	}
+	if err := interceptor(context.Background(), "/test.Service/Call", nil, nil, nil, invoker); status.Code(err) != codes.InvalidArgument || attempts != 1 {
+		t.Fatalf("error = %v after %d attempts, want InvalidArgument after 1", err, attempts)
+	}
+}
+
+func TestRetryBudgetMinPerSec(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	b := &retryBudget{minPerSec: 2, now: func() time.Time { return now }}
+	for i, want := range []bool{true, true, false} {
+		if got := b.withdraw(); got != want {
+			t.Fatalf("withdraw %d = %v, want %v", i, got, want)
+		}
+	}
+	now = now.Add(time.Second)
+	if !b.withdraw() {
+		t.Fatal("withdraw refused in the next second")
+	}
+}
+
+func TestRetryBudgetMaxTokens(t *testing.T) {
+	b := &retryBudget{ratio: 1, now: time.Now}
+	for i := 0; i < 10*retryBudgetMaxTokens; i++ {
+		b.deposit()
+	}
+	allowed := 0
+	for b.withdraw() {
+		allowed++
+	}
+	if allowed != retryBudgetMaxTokens {
+		t.Fatalf("%d retries allowed, want %d", allowed, retryBudgetMaxTokens)
+	}
+}
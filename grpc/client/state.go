@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Warmup connects conn and waits until it is ready, so that the first call does not pay for the connection.
+// It returns ctx error when the connection is not ready before ctx is done, the connection keeps trying in the background.
+func Warmup(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
+
+// WatchState calls fn with the new state of conn on every transition, until ctx is done.
+// It blocks, run it in its own goroutine.
+func WatchState(ctx context.Context, conn *grpc.ClientConn, fn func(connectivity.State)) {
+	state := conn.GetState()
+	for conn.WaitForStateChange(ctx, state) {
+		state = conn.GetState()
+		fn(state)
+	}
+}
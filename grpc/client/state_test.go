@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/stats"
+)
+
+// serveAt serves the test service on addr until the returned server is stopped.
+func serveAt(t *testing.T, addr string) *grpc.Server {
+	t.Helper()
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	testpb.RegisterTestServiceServer(s, &testService{})
+	go func() { _ = s.Serve(lis) }()
+	return s
+}
+
+// waitState reads states until one matches want.
+func waitState(t *testing.T, states <-chan connectivity.State, want func(connectivity.State) bool) connectivity.State {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case state := <-states:
+			if want(state) {
+				return state
+			}
+		case <-timeout:
+			t.Fatal("expected state transition not observed")
+		}
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	addr := freeAddr(t)
+	s := serveAt(t, addr)
+	defer func() { s.Stop() }()
+
+	_, conn, err := NewClientRaw(addr, testpb.NewTestServiceClient)
+	if err != nil {
+		t.Fatalf("NewClientRaw: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	states := make(chan connectivity.State, 64)
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		WatchState(ctx, conn, func(state connectivity.State) { states <- state })
+	}()
+
+	isReady := func(state connectivity.State) bool { return state == connectivity.Ready }
+	conn.Connect()
+	waitState(t, states, isReady)
+
+	s.Stop()
+	waitState(t, states, func(state connectivity.State) bool { return state != connectivity.Ready })
+
+	s = serveAt(t, addr)
+	conn.Connect()
+	waitState(t, states, isReady)
+
+	cancel()
+	select {
+	case <-watched:
+	case <-time.After(time.Second):
+		t.Fatal("WatchState did not return once the context was done")
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	_, conn, err := NewClientRaw("bufnet", testpb.NewTestServiceClient, startBufconnServer(t, &testService{})...)
+	if err != nil {
+		t.Fatalf("NewClientRaw: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Warmup(ctx, conn); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Fatalf("state after warmup = %v, want READY", state)
+	}
+}
+
+func TestWarmupTimeout(t *testing.T) {
+	_, conn, err := NewClientRaw(freeAddr(t), testpb.NewTestServiceClient)
+	if err != nil {
+		t.Fatalf("NewClientRaw: %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := Warmup(ctx, conn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Warmup = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// connCounter is a stats.Handler counting the connections established.
+type connCounter struct {
+	conns int32
+}
+
+func (c *connCounter) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context   { return ctx }
+func (c *connCounter) HandleRPC(context.Context, stats.RPCStats)                         {}
+func (c *connCounter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (c *connCounter) HandleConn(_ context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); ok {
+		atomic.AddInt32(&c.conns, 1)
+	}
+}
+
+func TestClientOptionsWarmup(t *testing.T) {
+	counter := &connCounter{}
+	client, closeFunc, err := NewClientWithOptions("bufnet", testpb.NewTestServiceClient, ClientOptions{
+		WarmupTimeout: 5 * time.Second,
+		DialOptions:   append([]grpc.DialOption{grpc.WithStatsHandler(counter)}, startBufconnServer(t, &testService{})[:1]...),
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithOptions: %v", err)
+	}
+	defer closeFunc()
+	// The connection is established before NewClientWithOptions returns, rather than by the first call.
+	if got := atomic.LoadInt32(&counter.conns); got != 1 {
+		t.Fatalf("%d connections before the first call, want 1", got)
+	}
+	if _, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{}); err != nil {
+		t.Fatalf("UnaryCall: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// RecvStream is the receiving side of a server streaming call, implemented by the generated stream clients.
+type RecvStream[M any] interface {
+	Recv() (M, error)
+	Context() context.Context
+}
+
+// Collect receives every message of stream until the server closes it and returns them.
+// It stops with the context error as soon as the context of the stream is done.
+// Example:
+//
+//	stream, err := client.List(ctx, req)
+//	items, err := Collect[*examplev1.Item](stream)
+func Collect[M any](stream RecvStream[M]) ([]M, error) {
+	var msgs []M
+	for {
+		if err := stream.Context().Err(); err != nil {
+			return msgs, err
+		}
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return msgs, nil
+		}
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+}
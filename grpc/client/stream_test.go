@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+)
+
+// streamRequest asks for n messages, the i-th one of i bytes.
+func streamRequest(n int) *testpb.StreamingOutputCallRequest {
+	req := &testpb.StreamingOutputCallRequest{}
+	for i := 0; i < n; i++ {
+		req.ResponseParameters = append(req.ResponseParameters, &testpb.ResponseParameters{Size: int32(i)})
+	}
+	return req
+}
+
+// cancelingStream cancels the context of the call once after messages have been received.
+type cancelingStream struct {
+	testpb.TestService_StreamingOutputCallClient
+	after  int
+	cancel context.CancelFunc
+}
+
+func (s *cancelingStream) Recv() (*testpb.StreamingOutputCallResponse, error) {
+	msg, err := s.TestService_StreamingOutputCallClient.Recv()
+	if s.after--; s.after == 0 {
+		s.cancel()
+	}
+	return msg, err
+}
+
+func TestCollect(t *testing.T) {
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, startBufconnServer(t, &testService{})...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+
+	stream, err := client.StreamingOutputCall(context.Background(), streamRequest(10))
+	if err != nil {
+		t.Fatalf("StreamingOutputCall: %v", err)
+	}
+	msgs, err := Collect[*testpb.StreamingOutputCallResponse](stream)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(msgs) != 10 {
+		t.Fatalf("collected %d messages, want 10", len(msgs))
+	}
+	for i, msg := range msgs {
+		if got := len(msg.GetPayload().GetBody()); got != i {
+			t.Fatalf("message %d has %d bytes, want %d", i, got, i)
+		}
+	}
+}
+
+func TestCollectCanceled(t *testing.T) {
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, startBufconnServer(t, &testService{})...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamingOutputCall(ctx, streamRequest(10))
+	if err != nil {
+		t.Fatalf("StreamingOutputCall: %v", err)
+	}
+	msgs, err := Collect[*testpb.StreamingOutputCallResponse](&cancelingStream{TestService_StreamingOutputCallClient: stream, after: 3, cancel: cancel})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Collect error = %v, want context.Canceled", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("collected %d messages, want the 3 received before the cancellation", len(msgs))
+	}
+}
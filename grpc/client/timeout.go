@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// CallWithTimeout calls fn with a child context of ctx which is cancelled after d.
+// If fn does not return before the deadline, ctx error (context.DeadlineExceeded) is returned.
+// Example:
+//
+//	res, err := CallWithTimeout(ctx, time.Second, func(ctx context.Context) (*examplev1.GetResponse, error) {
+//		return client.Get(ctx, req)
+//	})
+func CallWithTimeout[R any](ctx context.Context, d time.Duration, fn func(context.Context) (R, error)) (R, error) {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	type result struct {
+		res R
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := fn(ctx)
+		done <- result{res: res, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.res, r.err
+	case <-ctx.Done():
+		var res R
+		return res, ctx.Err()
+	}
+}
+
+// WithDefaultTimeout returns a unary client interceptor which sets a deadline of d on calls without one.
+// Calls which already carry a deadline keep it, whether it is shorter or longer than d.
+func WithDefaultTimeout(d time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
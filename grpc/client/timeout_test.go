@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallWithTimeoutDeadlineExceeded(t *testing.T) {
+	fnCtx := make(chan context.Context, 1)
+	res, err := CallWithTimeout(context.Background(), 10*time.Millisecond, func(ctx context.Context) (string, error) {
+		fnCtx <- ctx
+		time.Sleep(100 * time.Millisecond)
+		return "late", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || res != "" {
+		t.Fatalf("CallWithTimeout = (%q, %v), want context.DeadlineExceeded", res, err)
+	}
+	if err := (<-fnCtx).Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("context of fn error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCallWithTimeoutPassesResult(t *testing.T) {
+	res, err := CallWithTimeout(context.Background(), time.Second, func(ctx context.Context) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("context of fn has no deadline")
+		}
+		return "value", nil
+	})
+	if err != nil || res != "value" {
+		t.Fatalf("CallWithTimeout = (%q, %v), want value", res, err)
+	}
+
+	wantErr := errors.New("failed")
+	if _, err := CallWithTimeout(context.Background(), time.Second, func(ctx context.Context) (int, error) {
+		return 0, wantErr
+	}); !errors.Is(err, wantErr) {
+		t.Fatalf("CallWithTimeout error = %v, want %v", err, wantErr)
+	}
+}
+
+// sleepingService returns replies after sleeping for the requested number of milliseconds, or once the call is done.
+func sleepingService() *testService {
+	return &testService{unary: func(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+		select {
+		case <-time.After(time.Duration(req.GetResponseSize()) * time.Millisecond):
+			return &testpb.SimpleResponse{}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	opts := append(startBufconnServer(t, sleepingService()), ChainUnary(WithDefaultTimeout(100*time.Millisecond)))
+	client, closeFunc, err := NewClient("bufnet", testpb.NewTestServiceClient, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer closeFunc()
+	slow := &testpb.SimpleRequest{ResponseSize: 5000}
+
+	start := time.Now()
+	_, err = client.UnaryCall(context.Background(), slow)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("call without deadline = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("call without deadline failed after %s, want about 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = client.UnaryCall(ctx, slow)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("call with a shorter deadline = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Fatalf("call with a 10ms deadline failed after %s", elapsed)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.UnaryCall(ctx, &testpb.SimpleRequest{ResponseSize: 200}); err != nil {
+		t.Fatalf("call with a longer deadline: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadataKey is the metadata key carrying the token.
+	MetadataKey = "authorization"
+
+	bearerScheme = "bearer "
+)
+
+// ValidateFunc validates token and returns the context the handler is called with, e.g. enriched with the user.
+type ValidateFunc func(ctx context.Context, token string) (context.Context, error)
+
+// UnaryServerInterceptor returns a new unary server interceptor that authenticates calls with a bearer token.
+//
+// The token is read from the `authorization: Bearer <token>` metadata and passed to validate. Calls without a token
+// or whose token is rejected by validate fail with `Unauthenticated`, unless validate returns a GRPC status error
+// which is then returned as is. Methods listed in exemptMethods (full method names, e.g. "/pkg.Service/Method")
+// are not authenticated.
+func UnaryServerInterceptor(validate ValidateFunc, exemptMethods ...string) grpc.UnaryServerInterceptor {
+	exempt := make(map[string]struct{}, len(exemptMethods))
+	for _, m := range exemptMethods {
+		exempt[m] = struct{}{}
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := exempt[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		token, ok := BearerToken(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		newCtx, err := validate(ctx, token)
+		if err != nil {
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// BearerToken returns the bearer token of the incoming metadata, if any.
+func BearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, v := range md.Get(MetadataKey) {
+		if len(v) > len(bearerScheme) && strings.EqualFold(v[:len(bearerScheme)], bearerScheme) {
+			return strings.TrimSpace(v[len(bearerScheme):]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type userKey struct{}
+
+func validate(ctx context.Context, token string) (context.Context, error) {
+	switch token {
+	case "valid":
+		return context.WithValue(ctx, userKey{}, "alice"), nil
+	case "expired":
+		return nil, status.Error(codes.PermissionDenied, "token expired")
+	default:
+		return nil, errors.New("unknown token")
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(validate, "/grpc.health.v1.Health/Check")
+	for name, tc := range map[string]struct {
+		method   string
+		md       metadata.MD
+		wantCode codes.Code
+		wantUser interface{}
+	}{
+		"missing token":  {method: "/test.Service/Call", wantCode: codes.Unauthenticated},
+		"not bearer":     {method: "/test.Service/Call", md: metadata.Pairs(MetadataKey, "Basic dXNlcg=="), wantCode: codes.Unauthenticated},
+		"invalid token":  {method: "/test.Service/Call", md: metadata.Pairs(MetadataKey, "Bearer forged"), wantCode: codes.Unauthenticated},
+		"status error":   {method: "/test.Service/Call", md: metadata.Pairs(MetadataKey, "Bearer expired"), wantCode: codes.PermissionDenied},
+		"exempt method":  {method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+		"valid token":    {method: "/test.Service/Call", md: metadata.Pairs(MetadataKey, "Bearer valid"), wantCode: codes.OK, wantUser: "alice"},
+		"case of scheme": {method: "/test.Service/Call", md: metadata.Pairs(MetadataKey, "bearer valid"), wantCode: codes.OK, wantUser: "alice"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			var user interface{}
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+				user = ctx.Value(userKey{})
+				return nil, nil
+			})
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("error = %v, want %s", err, tc.wantCode)
+			}
+			if user != tc.wantUser {
+				t.Fatalf("user of the handler context = %v, want %v", user, tc.wantUser)
+			}
+		})
+	}
+}
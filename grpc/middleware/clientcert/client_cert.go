@@ -0,0 +1,46 @@
+package clientcert
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// PeerCommonName returns the common name of the certificate presented by the client over mTLS.
+// It returns false when the peer is unknown, the connection is not TLS or no certificate was presented.
+func PeerCommonName(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return "", false
+	}
+	return tlsInfo.State.PeerCertificates[0].Subject.CommonName, true
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that only lets clients with an allowed certificate through.
+//
+// Calls without a client certificate are rejected with `Unauthenticated`, calls whose certificate common name
+// is not in allowed are rejected with `PermissionDenied`.
+func UnaryServerInterceptor(allowed []string) grpc.UnaryServerInterceptor {
+	allowList := make(map[string]struct{}, len(allowed))
+	for _, cn := range allowed {
+		allowList[cn] = struct{}{}
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		cn, ok := PeerCommonName(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "client certificate is required")
+		}
+		if _, ok := allowList[cn]; !ok {
+			return nil, status.Errorf(codes.PermissionDenied, "client %q is not allowed", cn)
+		}
+		return handler(ctx, req)
+	}
+}
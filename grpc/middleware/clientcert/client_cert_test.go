@@ -0,0 +1,146 @@
+package clientcert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testCA issues certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a certificate for cn, valid for the server name "bufnet".
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"bufnet"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+type echoService struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (echoService) UnaryCall(context.Context, *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	return &testpb.SimpleResponse{}, nil
+}
+
+// startServer serves over mTLS with the interceptor allowing allowed and returns a listener to dial.
+func startServer(t *testing.T, ca *testCA, allowed []string) *bufconn.Listener {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "server", x509.ExtKeyUsageServerAuth)},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	})
+	s := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(UnaryServerInterceptor(allowed)))
+	testpb.RegisterTestServiceServer(s, echoService{})
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis
+}
+
+// call calls the server over lis, presenting certs.
+func call(t *testing.T, ca *testCA, lis *bufconn.Listener, certs ...tls.Certificate) error {
+	t.Helper()
+	creds := credentials.NewTLS(&tls.Config{RootCAs: ca.pool, Certificates: certs, ServerName: "bufnet"})
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	_, err = testpb.NewTestServiceClient(conn).UnaryCall(context.Background(), &testpb.SimpleRequest{})
+	return err
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ca := newTestCA(t)
+	lis := startServer(t, ca, []string{"billing"})
+
+	for name, tc := range map[string]struct {
+		certs []tls.Certificate
+		want  codes.Code
+	}{
+		"allowed":        {certs: []tls.Certificate{ca.issue(t, "billing", x509.ExtKeyUsageClientAuth)}, want: codes.OK},
+		"denied":         {certs: []tls.Certificate{ca.issue(t, "reporting", x509.ExtKeyUsageClientAuth)}, want: codes.PermissionDenied},
+		"no certificate": {want: codes.Unauthenticated},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := call(t, ca, lis, tc.certs...); status.Code(err) != tc.want {
+				t.Fatalf("UnaryCall error = %v, want %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestPeerCommonNameWithoutPeer(t *testing.T) {
+	if cn, ok := PeerCommonName(context.Background()); ok {
+		t.Fatalf("PeerCommonName = %q, want none", cn)
+	}
+}
@@ -0,0 +1,28 @@
+package deadline
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that rejects calls without a deadline.
+//
+// Such calls fail with `InvalidArgument`. Methods listed in exemptMethods (full method names, e.g.
+// "/grpc.health.v1.Health/Check") are let through without deadline.
+func UnaryServerInterceptor(exemptMethods ...string) grpc.UnaryServerInterceptor {
+	exempt := make(map[string]struct{}, len(exemptMethods))
+	for _, m := range exemptMethods {
+		exempt[m] = struct{}{}
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := exempt[info.FullMethod]; !ok {
+			if _, ok := ctx.Deadline(); !ok {
+				return nil, status.Errorf(codes.InvalidArgument, "%s requires a deadline, set a timeout on the call", info.FullMethod)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
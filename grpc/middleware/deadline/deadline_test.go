@@ -0,0 +1,41 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor("/grpc.health.v1.Health/Check")
+	withDeadline, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for name, tc := range map[string]struct {
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		"without deadline": {ctx: context.Background(), method: "/test.Service/Call", want: codes.InvalidArgument},
+		"with deadline":    {ctx: withDeadline, method: "/test.Service/Call", want: codes.OK},
+		"exempt method":    {ctx: context.Background(), method: "/grpc.health.v1.Health/Check", want: codes.OK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			_, err := interceptor(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(context.Context, interface{}) (interface{}, error) {
+				called = true
+				return nil, nil
+			})
+			if status.Code(err) != tc.want {
+				t.Fatalf("error = %v, want %s", err, tc.want)
+			}
+			if called != (tc.want == codes.OK) {
+				t.Fatalf("handler called: %t", called)
+			}
+		})
+	}
+}
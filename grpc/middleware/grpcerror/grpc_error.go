@@ -7,20 +7,33 @@ import (
 	// nolint:staticcheck
 	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/linhbkhn95/golang-british/apperror"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/internal/peeraddr"
 	"github.com/linhbkhn95/golang-british/logger"
 )
 
 // UnaryServerInterceptor returns a new unary server interceptor that wraps output error.
 //
-// Output error will be converted to GRPC error before sending to clients.
-func UnaryServerInterceptor(development bool, internalServerErr error) grpc.UnaryServerInterceptor {
-	w := grpcErrorWrapper{development: development, internalServerErr: internalServerErr}
+// Output error will be converted to GRPC error before sending to clients:
+//   - context.Canceled and context.DeadlineExceeded become `Canceled` and `DeadlineExceeded`.
+//   - GRPC errors, apperror errors and errors mapped by WithCodeMapper keep their code.
+//   - Any other error is unexpected: it is logged and internalServerErr is returned in production, so that its
+//     message never reaches clients, while development returns it as `Unknown` with its message.
+//
+// internalServerErr is returned for unexpected errors in production, a generic `Internal` error is used when nil.
+func UnaryServerInterceptor(development bool, internalServerErr error, opts ...Option) grpc.UnaryServerInterceptor {
+	if internalServerErr == nil {
+		internalServerErr = errInternalServer
+	}
+	o := newOptions(opts)
+	w := grpcErrorWrapper{development: development, internalServerErr: internalServerErr, codeMapper: o.codeMapper}
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		res, err := handler(ctx, req)
 		if err != nil {
-			return nil, w.GRPCError(err)
+			return nil, w.GRPCError(ctx, err)
 		}
 		return res, nil
 	}
@@ -39,22 +52,33 @@ func UnaryServerInterceptor(development bool, internalServerErr error) grpc.Unar
 //	}
 //}
 
+var errInternalServer = status.Error(codes.Internal, "internal server error")
+
 // grpcErrorWrapper is wrapper that convert app level error to GRPC error
 type grpcErrorWrapper struct {
 	development       bool
 	internalServerErr error
+	codeMapper        CodeMapper
 }
 
 // GRPCError converts original error to GRPC error which will then be converted to HTTP error by grpc-gateway.
 // Error may be wrapped, so must unwrap it to retrieve original error.
-func (w grpcErrorWrapper) GRPCError(err error) error {
+// ctx is the request context, it is used to enrich the log entries with the method and peer address.
+func (w grpcErrorWrapper) GRPCError(ctx context.Context, err error) error {
 	wrappedErr := unwrapErr(err)
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		wrappedErr = appErr
+	}
 	if wrappedErr == context.Canceled || wrappedErr == context.DeadlineExceeded {
 		return status.FromContextError(wrappedErr).Err()
 	}
+	// stt is Unknown when wrappedErr is not a GRPC error, it is then handled as unexpected below.
 	stt, ok := status.FromError(wrappedErr)
-	if !ok {
-		return status.FromContextError(wrappedErr).Err()
+	if !ok && w.codeMapper != nil {
+		if code, mapped := w.codeMapper(err); mapped {
+			stt, ok = status.New(code, wrappedErr.Error()), true
+		}
 	}
 	if de, ok := wrappedErr.(interface {
 		Details() []proto.Message
@@ -66,17 +90,33 @@ func (w grpcErrorWrapper) GRPCError(err error) error {
 
 	// In development mode, return raw error message.
 	if w.development {
-		logger.WithFields(logger.Fields{"error": err}).Warnf("getting error...")
+		fields := logFields(ctx, err)
+		fields["grpc.code"] = stt.Code().String()
+		logger.WithFields(fields).Warnf("getting error...")
 		return status.Error(stt.Code(), err.Error())
 	}
 
 	if ok {
 		return stt.Err()
 	}
-	logger.WithFields(logger.Fields{"error": err}).Error("unexpected error...")
+	fields := logFields(ctx, err)
+	fields["grpc.code"] = status.Code(w.internalServerErr).String()
+	logger.WithFields(fields).Error("unexpected error...")
 	return w.internalServerErr
 }
 
+// logFields returns the fields describing err and the request it belongs to.
+func logFields(ctx context.Context, err error) logger.Fields {
+	fields := logger.Fields{logger.ErrorKey(): err}
+	if method, ok := grpc.Method(ctx); ok {
+		fields["grpc.method"] = method
+	}
+	if addr := peeraddr.FromContext(ctx); addr != "" {
+		fields["peer"] = addr
+	}
+	return fields
+}
+
 func unwrapErr(err error) error {
 	wrappedErr := errors.Unwrap(err)
 	if wrappedErr != nil {
@@ -0,0 +1,262 @@
+package grpcerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/linhbkhn95/golang-british/apperror"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+// serverStream is the transport stream of a call to method, so that grpc.Method works on its context.
+type serverStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s serverStream) Method() string { return s.method }
+
+// callContext returns the context of a call to method from 10.0.0.1:5000.
+func callContext(method string) context.Context {
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), serverStream{method: method})
+	return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+}
+
+// call runs interceptor over a handler returning err.
+func call(interceptor grpc.UnaryServerInterceptor, ctx context.Context, err error) error {
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, func(context.Context, interface{}) (interface{}, error) {
+		return nil, err
+	})
+	return err
+}
+
+func TestLoggedFieldsIncludeMethodAndPeer(t *testing.T) {
+	for name, development := range map[string]bool{"development": true, "production": false} {
+		t.Run(name, func(t *testing.T) {
+			ctx := callContext("/test.Service/Call")
+			err := call(UnaryServerInterceptor(development, nil), ctx, errors.New("boom"))
+			if status.Code(err) == codes.OK {
+				t.Fatal("error lost")
+			}
+			es := logs.Entries(t)
+			if len(es) != 1 {
+				t.Fatalf("got %d entries, want 1", len(es))
+			}
+			if es[0]["grpc.method"] != "/test.Service/Call" {
+				t.Errorf("grpc.method = %v, want /test.Service/Call", es[0]["grpc.method"])
+			}
+			if es[0]["peer"] != "10.0.0.1:5000" {
+				t.Errorf("peer = %v, want 10.0.0.1:5000", es[0]["peer"])
+			}
+		})
+	}
+}
+
+// errorService fails every unary call with err.
+type errorService struct {
+	testpb.UnimplementedTestServiceServer
+	err error
+}
+
+func (s errorService) UnaryCall(context.Context, *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	return nil, s.err
+}
+
+// callServer calls a server running interceptor over a handler failing with err and returns the error of the client.
+func callServer(t *testing.T, interceptor grpc.UnaryServerInterceptor, err error) error {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+	testpb.RegisterTestServiceServer(s, errorService{err: err})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	_, err = testpb.NewTestServiceClient(conn).UnaryCall(context.Background(), &testpb.SimpleRequest{})
+	return err
+}
+
+func TestAppErrorEndToEnd(t *testing.T) {
+	appErr := apperror.New(codes.NotFound, "user not found", wrapperspb.String("user_id"))
+	err := callServer(t, UnaryServerInterceptor(false, nil), fmt.Errorf("get user: %w", appErr))
+
+	stt := status.Convert(err)
+	if stt.Code() != codes.NotFound || stt.Message() != "user not found" {
+		t.Fatalf("status = %v, want NotFound user not found", stt)
+	}
+	details := stt.Details()
+	if len(details) != 1 {
+		t.Fatalf("details = %v, want one", details)
+	}
+	if v, ok := details[0].(*wrapperspb.StringValue); !ok || v.GetValue() != "user_id" {
+		t.Fatalf("detail = %v, want user_id", details[0])
+	}
+}
+
+func TestNilInternalServerErr(t *testing.T) {
+	err := call(UnaryServerInterceptor(false, nil), context.Background(), errors.New("database is down"))
+	if err == nil || status.Code(err) != codes.Internal {
+		t.Fatalf("error = %v, want Internal", err)
+	}
+	if strings.Contains(status.Convert(err).Message(), "database") {
+		t.Fatalf("message %q leaks the unexpected error", status.Convert(err).Message())
+	}
+	logs.Entries(t)
+
+	custom := status.Error(codes.Unavailable, "try again later")
+	if err := call(UnaryServerInterceptor(false, custom), context.Background(), errors.New("database is down")); err != custom {
+		t.Fatalf("error = %v, want %v", err, custom)
+	}
+	logs.Entries(t)
+}
+
+func TestPlainAndContextErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err         error
+		development bool
+		wantCode    codes.Code
+		wantMsg     string
+	}{
+		"plain error in production":        {err: errors.New("database is down"), wantCode: codes.Internal, wantMsg: "internal server error"},
+		"plain error in development":       {err: errors.New("database is down"), development: true, wantCode: codes.Unknown, wantMsg: "database is down"},
+		"canceled in production":           {err: context.Canceled, wantCode: codes.Canceled, wantMsg: context.Canceled.Error()},
+		"canceled in development":          {err: context.Canceled, development: true, wantCode: codes.Canceled, wantMsg: context.Canceled.Error()},
+		"wrapped canceled":                 {err: fmt.Errorf("query: %w", context.Canceled), wantCode: codes.Canceled, wantMsg: context.Canceled.Error()},
+		"deadline exceeded in production":  {err: context.DeadlineExceeded, wantCode: codes.DeadlineExceeded, wantMsg: context.DeadlineExceeded.Error()},
+		"deadline exceeded in development": {err: context.DeadlineExceeded, development: true, wantCode: codes.DeadlineExceeded, wantMsg: context.DeadlineExceeded.Error()},
+		"wrapped deadline exceeded":        {err: fmt.Errorf("query: %w", context.DeadlineExceeded), wantCode: codes.DeadlineExceeded, wantMsg: context.DeadlineExceeded.Error()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := call(UnaryServerInterceptor(tc.development, nil), callContext("/test.Service/Call"), tc.err)
+			logs.Entries(t)
+			if stt := status.Convert(err); stt.Code() != tc.wantCode || stt.Message() != tc.wantMsg {
+				t.Fatalf("status = %v, want %v %q", stt, tc.wantCode, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestDevelopmentLogsCode(t *testing.T) {
+	ctx := callContext("/test.Service/Call")
+	err := call(UnaryServerInterceptor(true, nil), ctx, status.Error(codes.NotFound, "user not found"))
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("error = %v, want code NotFound", err)
+	}
+	es := logs.Entries(t)
+	if len(es) != 1 {
+		t.Fatalf("got %d entries, want 1", len(es))
+	}
+	for key, want := range map[string]interface{}{
+		"level":       "warn",
+		"grpc.code":   "NotFound",
+		"grpc.method": "/test.Service/Call",
+		"error":       "rpc error: code = NotFound desc = user not found",
+	} {
+		if got := es[0][key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestUnexpectedErrorLogsCode(t *testing.T) {
+	err := call(UnaryServerInterceptor(false, nil), callContext("/test.Service/Call"), errors.New("boom"))
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("error = %v, want code Internal", err)
+	}
+	es := logs.Entries(t)
+	if len(es) != 1 || es[0]["grpc.code"] != "Internal" {
+		t.Fatalf("entries %v, want one with grpc.code Internal", es)
+	}
+
+	// Known codes are returned silently in production.
+	if err := call(UnaryServerInterceptor(false, nil), callContext("/test.Service/Call"), status.Error(codes.NotFound, "user not found")); status.Code(err) != codes.NotFound {
+		t.Fatalf("error = %v, want code NotFound", err)
+	}
+	if es := logs.Entries(t); len(es) != 0 {
+		t.Fatalf("entries %v, want none", es)
+	}
+}
+
+func TestErrorKey(t *testing.T) {
+	logger.SetErrorKey("err")
+	t.Cleanup(func() { logger.SetErrorKey("") })
+	for name, development := range map[string]bool{"development": true, "production": false} {
+		t.Run(name, func(t *testing.T) {
+			call(UnaryServerInterceptor(development, nil), callContext("/test.Service/Call"), errors.New("boom"))
+			es := logs.Entries(t)
+			if len(es) != 1 {
+				t.Fatalf("got %d entries, want 1", len(es))
+			}
+			if es[0]["err"] != "boom" {
+				t.Errorf("err = %v, want boom", es[0]["err"])
+			}
+			if got, ok := es[0]["error"]; ok {
+				t.Errorf("error = %v, want absent", got)
+			}
+		})
+	}
+}
+
+var errNotFound = errors.New("user not found")
+
+func TestWithCodeMapper(t *testing.T) {
+	consulted := 0
+	interceptor := UnaryServerInterceptor(false, nil, WithCodeMapper(func(err error) (codes.Code, bool) {
+		consulted++
+		if errors.Is(err, errNotFound) {
+			return codes.NotFound, true
+		}
+		return codes.Unknown, false
+	}))
+	for name, tc := range map[string]struct {
+		err           error
+		wantCode      codes.Code
+		wantMsg       string
+		wantConsulted int
+	}{
+		"mapped":      {err: fmt.Errorf("get user 7: %w", errNotFound), wantCode: codes.NotFound, wantMsg: "user not found", wantConsulted: 1},
+		"not mapped":  {err: errors.New("boom"), wantCode: codes.Internal, wantMsg: "internal server error", wantConsulted: 1},
+		"grpc status": {err: status.Error(codes.AlreadyExists, "user exists"), wantCode: codes.AlreadyExists, wantMsg: "user exists"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			consulted = 0
+			st := status.Convert(call(interceptor, callContext("/test.Service/Call"), tc.err))
+			if st.Code() != tc.wantCode || st.Message() != tc.wantMsg {
+				t.Fatalf("error = %v, want code %s and message %q", st.Err(), tc.wantCode, tc.wantMsg)
+			}
+			if consulted != tc.wantConsulted {
+				t.Fatalf("mapper consulted %d times, want %d", consulted, tc.wantConsulted)
+			}
+			logs.Entries(t)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package grpcerror
+
+import "google.golang.org/grpc/codes"
+
+// CodeMapper returns the code of err and true, or false when err is not one it maps.
+type CodeMapper func(err error) (codes.Code, bool)
+
+// Option configures the error interceptor.
+type Option func(*options)
+
+type options struct {
+	codeMapper CodeMapper
+}
+
+// WithCodeMapper sets the CodeMapper consulted for errors which are not GRPC errors, before they are handled
+// as unexpected, e.g. to map domain errors to codes with a table rather than with a GRPCStatus method per error.
+// The mapped error is returned with its message, wrapping error excluded.
+//
+//	grpcerror.WithCodeMapper(func(err error) (codes.Code, bool) {
+//		switch {
+//		case errors.Is(err, ErrNotFound):
+//			return codes.NotFound, true
+//		default:
+//			return codes.Unknown, false
+//		}
+//	})
+func WithCodeMapper(mapper CodeMapper) Option {
+	return func(o *options) {
+		o.codeMapper = mapper
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
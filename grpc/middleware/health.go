@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthBuckets is the number of buckets the window is split into, the window slides one bucket at a time.
+const healthBuckets = 10
+
+type healthBucket struct {
+	index  int64
+	total  int
+	failed int
+}
+
+// errorWindow counts the calls and the failed ones over the last healthBuckets buckets of width.
+type errorWindow struct {
+	width   time.Duration
+	buckets [healthBuckets]healthBucket
+}
+
+// add records a call at t.
+func (w *errorWindow) add(t time.Time, failed bool) {
+	index := t.UnixNano() / int64(w.width)
+	b := &w.buckets[index%healthBuckets]
+	if b.index != index {
+		*b = healthBucket{index: index}
+	}
+	b.total++
+	if failed {
+		b.failed++
+	}
+}
+
+// counts returns the number of calls and of failed ones in the window ending at t.
+func (w *errorWindow) counts(t time.Time) (total, failed int) {
+	index := t.UnixNano() / int64(w.width)
+	for _, b := range w.buckets {
+		if index-b.index < healthBuckets {
+			total += b.total
+			failed += b.failed
+		}
+	}
+	return total, failed
+}
+
+// healthTracker flips the status of service on h from the error rate of its window.
+type healthTracker struct {
+	mu        sync.Mutex
+	window    errorWindow
+	h         *health.Server
+	service   string
+	threshold float64
+	minCalls  int
+	degraded  bool
+}
+
+func (t *healthTracker) record(now time.Time, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window.add(now, failed)
+	t.evaluateLocked(now)
+}
+
+func (t *healthTracker) evaluate(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.evaluateLocked(now)
+}
+
+func (t *healthTracker) evaluateLocked(now time.Time) {
+	total, failed := t.window.counts(now)
+	unhealthy := total >= t.minCalls && total > 0 && float64(failed)/float64(total) > t.threshold
+	switch {
+	case unhealthy && !t.degraded:
+		t.degraded = true
+		t.h.SetServingStatus(t.service, healthpb.HealthCheckResponse_NOT_SERVING)
+	case !unhealthy && t.degraded:
+		t.degraded = false
+		t.h.SetServingStatus(t.service, healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+// isServerFailure reports whether err means the server failed rather than the request being rejected.
+func isServerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// ErrorRateHealthInterceptor returns a new unary server interceptor that marks service as NOT_SERVING on h
+// when at least minCalls calls were made over the last window and the rate of failed ones exceeds threshold,
+// between 0 and 1, and back as SERVING once it does not anymore. Only server failures count: `Unknown`,
+// `Internal`, `Unavailable`, `DataLoss` and `DeadlineExceeded`, so that invalid requests do not degrade the service.
+//
+// The status is evaluated on every call and every tenth of window, so a degraded service recovers once the
+// failed calls leave the window, even when health checking clients stopped sending traffic. It is only set
+// back to SERVING if the interceptor degraded it, and never once h is shut down.
+// The returned stop func stops the periodic evaluation, it must be called once the server is stopped.
+func ErrorRateHealthInterceptor(h *health.Server, service string, threshold float64, minCalls int, window time.Duration) (grpc.UnaryServerInterceptor, func()) {
+	width := window / healthBuckets
+	if width <= 0 {
+		width = 1
+	}
+	t := &healthTracker{
+		window:    errorWindow{width: width},
+		h:         h,
+		service:   service,
+		threshold: threshold,
+		minCalls:  minCalls,
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(width)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				t.evaluate(now)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		t.record(time.Now(), isServerFailure(err))
+		return res, err
+	}
+	return interceptor, stop
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func servingStatus(t *testing.T, h *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	res, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("check %q: %v", service, err)
+	}
+	return res.Status
+}
+
+func newTestTracker(h *health.Server) *healthTracker {
+	return &healthTracker{
+		window:    errorWindow{width: time.Second},
+		h:         h,
+		service:   "svc",
+		threshold: 0.5,
+		minCalls:  4,
+	}
+}
+
+func TestHealthTrackerTransitions(t *testing.T) {
+	h := health.NewServer()
+	h.SetServingStatus("svc", healthpb.HealthCheckResponse_SERVING)
+	tr := newTestTracker(h)
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 3; i++ {
+		tr.record(start, true)
+	}
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status below minCalls = %v, want SERVING", got)
+	}
+
+	tr.record(start, true)
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after burst = %v, want NOT_SERVING", got)
+	}
+
+	// No more traffic: the failed calls leave the window and the service recovers without any call.
+	tr.evaluate(start.Add(5 * time.Second))
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status within window = %v, want NOT_SERVING", got)
+	}
+	tr.evaluate(start.Add(10 * time.Second))
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status after window = %v, want SERVING", got)
+	}
+}
+
+func TestHealthTrackerRecoversOnSuccess(t *testing.T) {
+	h := health.NewServer()
+	tr := newTestTracker(h)
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 4; i++ {
+		tr.record(now, true)
+	}
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after burst = %v, want NOT_SERVING", got)
+	}
+	for i := 0; i < 4; i++ {
+		tr.record(now, false)
+	}
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status at rate 0.5 = %v, want SERVING", got)
+	}
+}
+
+func TestHealthTrackerKeepsExternalStatus(t *testing.T) {
+	h := health.NewServer()
+	h.SetServingStatus("svc", healthpb.HealthCheckResponse_NOT_SERVING)
+	tr := newTestTracker(h)
+
+	tr.record(time.Unix(1000, 0), false)
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v, want NOT_SERVING set by the application", got)
+	}
+}
+
+func TestErrorRateHealthInterceptor(t *testing.T) {
+	h := health.NewServer()
+	h.SetServingStatus("svc", healthpb.HealthCheckResponse_SERVING)
+	interceptor, stop := ErrorRateHealthInterceptor(h, "svc", 0.5, 3, 100*time.Millisecond)
+	defer stop()
+
+	call := func(err error) {
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return nil, err })
+	}
+	for i := 0; i < 5; i++ {
+		call(status.Error(codes.InvalidArgument, "invalid"))
+	}
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status after client errors = %v, want SERVING", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		call(status.Error(codes.Internal, "boom"))
+	}
+	if got := servingStatus(t, h, "svc"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after server errors = %v, want NOT_SERVING", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for servingStatus(t, h, "svc") != healthpb.HealthCheckResponse_SERVING {
+		if time.Now().After(deadline) {
+			t.Fatal("service did not recover once the window emptied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
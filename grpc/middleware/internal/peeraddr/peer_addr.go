@@ -0,0 +1,17 @@
+// Package peeraddr reads the client address of incoming calls for the middlewares, see middleware.PeerAddr.
+package peeraddr
+
+import (
+	"context"
+
+	"google.golang.org/grpc/peer"
+)
+
+// FromContext returns the address of the client of the incoming call, empty when it is unknown.
+func FromContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
@@ -0,0 +1,27 @@
+package peeraddr
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestFromContext(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		want string
+	}{
+		"no peer":      {ctx: context.Background(), want: ""},
+		"no address":   {ctx: peer.NewContext(context.Background(), &peer.Peer{}), want: ""},
+		"with address": {ctx: peer.NewContext(context.Background(), &peer.Peer{Addr: addr}), want: "10.0.0.1:5000"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := FromContext(tc.ctx); got != tc.want {
+				t.Fatalf("FromContext = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package latencysummary
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// sampleSize is the number of latencies kept per method and interval, calls beyond it are sampled
+// uniformly so that memory stays bounded whatever the traffic.
+const sampleSize = 1024
+
+// reservoir is a uniform sample of the latencies of one method, see Algorithm R.
+type reservoir struct {
+	count   int
+	samples []time.Duration
+}
+
+func (r *reservoir) add(d time.Duration, rnd *rand.Rand) {
+	r.count++
+	if len(r.samples) < sampleSize {
+		r.samples = append(r.samples, d)
+		return
+	}
+	if i := rnd.Intn(r.count); i < sampleSize {
+		r.samples[i] = d
+	}
+}
+
+// quantile returns the q quantile of the sorted samples, in milliseconds.
+func quantile(sorted []time.Duration, q float64) float64 {
+	i := int(q * float64(len(sorted)-1))
+	return float64(sorted[i]) / float64(time.Millisecond)
+}
+
+type summary struct {
+	mu      sync.Mutex
+	rnd     *rand.Rand
+	methods map[string]*reservoir
+}
+
+func (s *summary) add(method string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.methods[method]
+	if !ok {
+		r = &reservoir{}
+		s.methods[method] = r
+	}
+	r.add(d, s.rnd)
+}
+
+// flush logs one line per method seen since the previous flush and resets the samples.
+func (s *summary) flush() {
+	s.mu.Lock()
+	methods := s.methods
+	s.methods = make(map[string]*reservoir, len(methods))
+	s.mu.Unlock()
+
+	for method, r := range methods {
+		sort.Slice(r.samples, func(i, j int) bool { return r.samples[i] < r.samples[j] })
+		logger.WithFields(logger.Fields{
+			"grpc.method":  method,
+			"grpc.count":   r.count,
+			"grpc.p50_ms":  quantile(r.samples, 0.5),
+			"grpc.p90_ms":  quantile(r.samples, 0.9),
+			"grpc.p99_ms":  quantile(r.samples, 0.99),
+			"grpc.samples": len(r.samples),
+		}).Info("latency summary")
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that records the latency of every call
+// and, every interval, logs one summary line per method with the count and the p50, p90 and p99 latencies
+// in milliseconds, before starting over.
+//
+// Percentiles are computed on a uniform sample of at most 1024 calls per method and interval.
+// The returned stop func stops the periodic summary, it must be called once the server is stopped.
+func UnaryServerInterceptor(interval time.Duration) (grpc.UnaryServerInterceptor, func()) {
+	s := &summary{
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		methods: make(map[string]*reservoir),
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		s.add(info.FullMethod, time.Since(start))
+		return res, err
+	}
+	return interceptor, stop
+}
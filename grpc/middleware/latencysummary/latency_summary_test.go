@@ -0,0 +1,94 @@
+package latencysummary
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+func TestSummaryPercentiles(t *testing.T) {
+	s := &summary{rnd: rand.New(rand.NewSource(1)), methods: make(map[string]*reservoir)}
+	// 1ms to 100ms, in an order unrelated to their value.
+	for _, i := range rand.New(rand.NewSource(2)).Perm(100) {
+		s.add("/test.Service/Call", time.Duration(i+1)*time.Millisecond)
+	}
+	s.add("/test.Service/Other", 5*time.Millisecond)
+	logs.Entries(t)
+
+	s.flush()
+	entries := logs.Entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want one per method", len(entries))
+	}
+	for _, entry := range entries {
+		if entry["grpc.method"] != "/test.Service/Call" {
+			continue
+		}
+		for key, want := range map[string]float64{"grpc.count": 100, "grpc.samples": 100, "grpc.p50_ms": 50, "grpc.p90_ms": 90, "grpc.p99_ms": 99} {
+			if got := entry[key]; got != want {
+				t.Errorf("%s = %v, want %v", key, got, want)
+			}
+		}
+	}
+
+	// Flushing resets the samples, nothing is logged without calls.
+	s.flush()
+	if entries := logs.Entries(t); len(entries) != 0 {
+		t.Fatalf("entries %v after reset, want none", entries)
+	}
+}
+
+func TestReservoirBounded(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	r := &reservoir{}
+	for i := 0; i < 10*sampleSize; i++ {
+		r.add(time.Duration(i), rnd)
+	}
+	if r.count != 10*sampleSize || len(r.samples) != sampleSize {
+		t.Fatalf("count %d and %d samples, want %d and %d", r.count, len(r.samples), 10*sampleSize, sampleSize)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor, stop := UnaryServerInterceptor(20 * time.Millisecond)
+	defer stop()
+	logs.Entries(t)
+	for i := 0; i < 10; i++ {
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, func(context.Context, interface{}) (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			return nil, nil
+		})
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		entries := logs.Entries(t)
+		if len(entries) > 0 {
+			entry := entries[0]
+			if entry["msg"] != "latency summary" || entry["grpc.count"] != float64(10) {
+				t.Fatalf("entry %v, want the summary of 10 calls", entry)
+			}
+			p50, p99 := entry["grpc.p50_ms"].(float64), entry["grpc.p99_ms"].(float64)
+			if p50 < 1 || p99 < p50 {
+				t.Fatalf("p50 %vms and p99 %vms, want at least 1ms and increasing", p50, p99)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no summary logged")
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/internal/peeraddr"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that logs every call.
+//
+// Successful calls are logged at info level, failed ones at error level, with the method, status code,
+// duration, peer address and request id.
+//
+// The handler's context carries a logger with the method and the request id, retrieved with logger.FromContext
+// so that the lines of the handler can be correlated with the call.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		requestFields := logger.Fields{"grpc.method": info.FullMethod}
+		if id, ok := requestid.FromContext(ctx); ok {
+			requestFields["request_id"] = id
+		}
+		reqLogger := logger.FromContext(ctx).WithFields(requestFields)
+		res, err := handler(logger.ToContext(ctx, reqLogger), req)
+
+		level := "info"
+		if err != nil {
+			level = "error"
+		}
+		if !o.enabled(info.FullMethod, level) || !o.sampled(err) {
+			return res, err
+		}
+
+		fields := logger.Fields{
+			"grpc.code":    status.Code(err).String(),
+			"grpc.time_ms": time.Since(start).Milliseconds(),
+		}
+		if addr := peeraddr.FromContext(ctx); addr != "" {
+			fields["peer"] = addr
+		}
+		if err != nil {
+			fields[logger.ErrorKey()] = err
+			reqLogger.WithFields(fields).Error("finished call")
+		} else {
+			reqLogger.WithFields(fields).Info("finished call")
+		}
+		return res, err
+	}
+}
@@ -0,0 +1,171 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+// call runs interceptor for method over a handler returning err.
+func call(interceptor grpc.UnaryServerInterceptor, method string, err error) {
+	_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+		return nil, err
+	})
+}
+
+func TestWithMethodLevels(t *testing.T) {
+	interceptor := UnaryServerInterceptor(WithMethodLevels(map[string]string{
+		"/grpc.health.v1.Health/Check": "error",
+		"/test.Service/Call":           "info",
+	}))
+	logs.Entries(t)
+
+	call(interceptor, "/grpc.health.v1.Health/Check", nil)
+	if es := logs.Entries(t); len(es) != 0 {
+		t.Fatalf("successful call of a method at error logged %v", es)
+	}
+
+	call(interceptor, "/grpc.health.v1.Health/Check", errors.New("boom"))
+	if es := logs.Entries(t); len(es) != 1 || es[0]["level"] != "error" {
+		t.Fatalf("failed call of a method at error logged %v, want one error entry", es)
+	}
+
+	for _, method := range []string{"/test.Service/Call", "/test.Service/Unlisted"} {
+		call(interceptor, method, nil)
+		es := logs.Entries(t)
+		if len(es) != 1 || es[0]["grpc.method"] != method || es[0]["level"] != "info" {
+			t.Fatalf("successful call of %s logged %v, want one info entry", method, es)
+		}
+	}
+}
+
+func TestCodeLoggedAsString(t *testing.T) {
+	logs.Entries(t)
+	call(UnaryServerInterceptor(), "/test.Service/Call", status.Error(codes.InvalidArgument, "bad request"))
+	es := logs.Entries(t)
+	if len(es) != 1 || es[0]["grpc.code"] != "InvalidArgument" {
+		t.Fatalf("entries = %v, want grpc.code InvalidArgument", es)
+	}
+}
+
+func TestPeerAddressLogged(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	logs.Entries(t)
+
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	entries := logs.Entries(t)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	// bufconn addresses are all named after the listener.
+	if got := entries[0]["peer"]; got != "bufconn" {
+		t.Fatalf("peer = %v, want bufconn", got)
+	}
+
+	// Without peer information the field is omitted.
+	call(UnaryServerInterceptor(), "/test.Service/Call", nil)
+	if got, ok := logs.Entries(t)[0]["peer"]; ok {
+		t.Fatalf("peer = %v, want absent", got)
+	}
+}
+
+func TestHandlerLoggerFromContext(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	logs.Entries(t)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		logger.FromContext(ctx).Info("handler line")
+		return nil, nil
+	})
+	entries := logs.Entries(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the handler line and the call", len(entries))
+	}
+	for _, entry := range entries {
+		if entry["request_id"] != "req-1" || entry["grpc.method"] != "/test.Service/Call" {
+			t.Errorf("entry %v, want request_id req-1 and grpc.method /test.Service/Call", entry)
+		}
+	}
+	if entries[0]["msg"] != "handler line" {
+		t.Errorf("first entry %v, want the handler line", entries[0])
+	}
+}
+
+func TestWithSuccessSampleRate(t *testing.T) {
+	interceptor := UnaryServerInterceptor(WithSuccessSampleRate(0.25), WithSampleSource(rand.New(rand.NewSource(1)).Float64))
+	logs.Entries(t)
+
+	for i := 0; i < 1000; i++ {
+		call(interceptor, "/test.Service/Call", nil)
+	}
+	for i := 0; i < 100; i++ {
+		call(interceptor, "/test.Service/Call", status.Error(codes.Unavailable, "down"))
+	}
+	var successes, failures int
+	for _, entry := range logs.Entries(t) {
+		if entry["grpc.code"] == "OK" {
+			successes++
+		} else {
+			failures++
+		}
+	}
+	if failures != 100 {
+		t.Errorf("%d failed calls logged, want all 100", failures)
+	}
+	if successes < 200 || successes > 300 {
+		t.Errorf("%d of 1000 successful calls logged, want about 250", successes)
+	}
+}
+
+func TestWithSampleSource(t *testing.T) {
+	draws := []float64{0, 0.5, 0.1, 0.9}
+	interceptor := UnaryServerInterceptor(WithSuccessSampleRate(0.3), WithSampleSource(func() float64 {
+		v := draws[0]
+		draws = draws[1:]
+		return v
+	}))
+	logs.Entries(t)
+	for range draws {
+		call(interceptor, "/test.Service/Call", nil)
+	}
+	if entries := logs.Entries(t); len(entries) != 2 {
+		t.Fatalf("got %d entries, want the 2 calls drawn below the rate", len(entries))
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import "math/rand"
+
+// Option configures the logging interceptor.
+type Option func(*options)
+
+type options struct {
+	methodLevels map[string]int
+	successRate  float64
+	sample       func() float64
+}
+
+// levelRanks orders the levels from the least to the most severe.
+var levelRanks = map[string]int{
+	"debug": 0,
+	"info":  1,
+	"warn":  2,
+	"error": 3,
+	"fatal": 4,
+	"panic": 5,
+}
+
+// WithMethodLevels sets the minimum level of the line logged for the given full method names,
+// e.g. {"/grpc.health.v1.Health/Check": "error"} only logs failed health checks. Unknown levels are ignored.
+func WithMethodLevels(levels map[string]string) Option {
+	return func(o *options) {
+		for method, level := range levels {
+			if rank, ok := levelRanks[level]; ok {
+				o.methodLevels[method] = rank
+			}
+		}
+	}
+}
+
+// WithSuccessSampleRate logs only a fraction rate, between 0 and 1, of the successful calls, to cut noise.
+// Failed calls are always logged. All calls are logged by default.
+func WithSuccessSampleRate(rate float64) Option {
+	return func(o *options) {
+		o.successRate = rate
+	}
+}
+
+// WithSampleSource replaces the source of the numbers in [0, 1) drawn to sample successful calls, rand.Float64
+// by default, e.g. with a deterministic sequence in tests.
+func WithSampleSource(fn func() float64) Option {
+	return func(o *options) {
+		o.sample = fn
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{methodLevels: map[string]int{}, successRate: 1, sample: rand.Float64}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// enabled reports whether a line at level must be logged for method.
+func (o *options) enabled(method, level string) bool {
+	min, ok := o.methodLevels[method]
+	return !ok || levelRanks[level] >= min
+}
+
+// sampled reports whether a call must be logged, failed calls always are.
+func (o *options) sampled(err error) bool {
+	return err != nil || o.successRate >= 1 || o.sample() < o.successRate
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/internal/peeraddr"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// StreamServerInterceptor returns a new streaming server interceptor that logs every stream once it is closed.
+//
+// Like UnaryServerInterceptor, successful streams are logged at info level and failed ones at error level,
+// with the method, status code, duration, peer address and request id, plus the number of messages
+// sent and received. The stream context carries the request-scoped logger, see logger.FromContext.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := ss.Context()
+		requestFields := logger.Fields{"grpc.method": info.FullMethod}
+		if id, ok := requestid.FromContext(ctx); ok {
+			requestFields["request_id"] = id
+		}
+		reqLogger := logger.FromContext(ctx).WithFields(requestFields)
+		wrapped := &countingStream{ServerStream: ss, ctx: logger.ToContext(ctx, reqLogger)}
+		err := handler(srv, wrapped)
+
+		level := "info"
+		if err != nil {
+			level = "error"
+		}
+		if !o.enabled(info.FullMethod, level) || !o.sampled(err) {
+			return err
+		}
+
+		fields := logger.Fields{
+			"grpc.code":          status.Code(err).String(),
+			"grpc.time_ms":       time.Since(start).Milliseconds(),
+			"grpc.msgs_sent":     atomic.LoadInt64(&wrapped.sent),
+			"grpc.msgs_received": atomic.LoadInt64(&wrapped.received),
+		}
+		if addr := peeraddr.FromContext(ctx); addr != "" {
+			fields["peer"] = addr
+		}
+		if err != nil {
+			fields[logger.ErrorKey()] = err
+			reqLogger.WithFields(fields).Error("finished stream")
+		} else {
+			reqLogger.WithFields(fields).Info("finished stream")
+		}
+		return err
+	}
+}
+
+// countingStream counts the messages successfully sent and received on the stream.
+type countingStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     int64
+	received int64
+}
+
+func (s *countingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.sent, 1)
+	}
+	return err
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.received, 1)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// streamingService streams one message per response parameter, then fails when the payload is "fail".
+type streamingService struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (streamingService) StreamingOutputCall(req *testpb.StreamingOutputCallRequest, stream testpb.TestService_StreamingOutputCallServer) error {
+	for range req.GetResponseParameters() {
+		if err := stream.Send(&testpb.StreamingOutputCallResponse{}); err != nil {
+			return err
+		}
+	}
+	if string(req.GetPayload().GetBody()) == "fail" {
+		return status.Error(codes.ResourceExhausted, "quota exceeded")
+	}
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.StreamInterceptor(StreamServerInterceptor()))
+	testpb.RegisterTestServiceServer(s, streamingService{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := testpb.NewTestServiceClient(conn)
+
+	for name, tc := range map[string]struct {
+		body      string
+		wantCode  codes.Code
+		wantLevel string
+	}{
+		"success": {wantCode: codes.OK, wantLevel: "info"},
+		"failure": {body: "fail", wantCode: codes.ResourceExhausted, wantLevel: "error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			logs.Entries(t)
+			req := &testpb.StreamingOutputCallRequest{
+				Payload:            &testpb.Payload{Body: []byte(tc.body)},
+				ResponseParameters: make([]*testpb.ResponseParameters, 3),
+			}
+			for i := range req.ResponseParameters {
+				req.ResponseParameters[i] = &testpb.ResponseParameters{}
+			}
+			stream, err := client.StreamingOutputCall(context.Background(), req)
+			if err != nil {
+				t.Fatalf("StreamingOutputCall: %v", err)
+			}
+			for err == nil {
+				_, err = stream.Recv()
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("stream error = %v, want code %s", err, tc.wantCode)
+			}
+
+			entries := logs.Entries(t)
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			for key, want := range map[string]interface{}{
+				"msg":                "finished stream",
+				"level":              tc.wantLevel,
+				"grpc.method":        "/grpc.testing.TestService/StreamingOutputCall",
+				"grpc.code":          tc.wantCode.String(),
+				"grpc.msgs_sent":     float64(3),
+				"grpc.msgs_received": float64(1),
+			} {
+				if got := entries[0][key]; got != want {
+					t.Errorf("%s = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/grpcerror"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/logging"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/recovery"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+)
+
+// DefaultUnaryChain returns a ServerOption chaining the default unary interceptors, from the outer most:
+//
+//   - request-id: assigned first so that every log line below, including the recovered panic, carries it.
+//   - recovery: catches panics of everything below and turns them into `Internal` errors.
+//   - logging: logs the call with its final status code, once grpcerror converted the error.
+//   - grpcerror: converts the handler error to a GRPC error, closest to the handler.
+func DefaultUnaryChain(development bool, internalErr error) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(
+		requestid.UnaryServerInterceptor(),
+		recovery.UnaryServerInterceptor(),
+		logging.UnaryServerInterceptor(),
+		grpcerror.UnaryServerInterceptor(development, internalErr),
+	)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+type panickingService struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (panickingService) UnaryCall(context.Context, *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	panic("boom")
+}
+
+func TestDefaultUnaryChainRecoversPanic(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(DefaultUnaryChain(false, nil))
+	testpb.RegisterTestServiceServer(s, panickingService{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	logs.Entries(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestid.MetadataKey, "req-1")
+	_, err = testpb.NewTestServiceClient(conn).UnaryCall(ctx, &testpb.SimpleRequest{})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("UnaryCall error = %v, want Internal", err)
+	}
+
+	var recovered map[string]interface{}
+	for _, entry := range logs.Entries(t) {
+		if entry["msg"] == "recovered from panic" {
+			recovered = entry
+		}
+	}
+	if recovered == nil {
+		t.Fatal("panic not logged")
+	}
+	if recovered["request_id"] != "req-1" || recovered["panic"] != "boom" {
+		t.Fatalf("panic entry = %v, want request_id req-1 and panic boom", recovered)
+	}
+}
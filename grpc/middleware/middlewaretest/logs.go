@@ -0,0 +1,65 @@
+package middlewaretest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// Logs records the JSON lines of the global logger.
+//
+//	var logs *middlewaretest.Logs
+//
+//	func TestMain(m *testing.M) {
+//		logs = middlewaretest.CaptureLogs()
+//		os.Exit(m.Run())
+//	}
+type Logs struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// CaptureLogs initializes the global logger with the zap backend to encode every level as JSON into the
+// returned Logs. It panics if the logger cannot be initialized, it is meant to be called from TestMain.
+func CaptureLogs() *Logs {
+	l := &Logs{}
+	if _, err := logger.InitLogger(logger.Configuration{
+		EnableConsole:     true,
+		ConsoleJSONFormat: true,
+		ConsoleLevel:      "debug",
+		ConsoleWriter:     l,
+	}, logger.LoggerBackendZap); err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func (l *Logs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+// Entries decodes the recorded lines and forgets them.
+func (l *Logs) Entries(t testing.TB) []map[string]interface{} {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var res []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(l.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		res = append(res, entry)
+	}
+	l.buf.Reset()
+	return res
+}
@@ -0,0 +1,76 @@
+// Package middlewaretest provides helpers to test the composition of interceptors.
+package middlewaretest
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// Recorder records the order in which its interceptors are entered.
+//
+//	rec := middlewaretest.RecordOrder()
+//	conn, _ := grpc.Dial(addr, grpc.WithChainUnaryInterceptor(rec.UnaryClientInterceptor("a"), rec.UnaryClientInterceptor("b")))
+//	// after a call, rec.Order() is []string{"a", "b"}
+type Recorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// RecordOrder returns a new Recorder with no recorded names.
+func RecordOrder() *Recorder {
+	return &Recorder{}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that records name before calling the handler.
+func (r *Recorder) UnaryServerInterceptor(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		r.record(name)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that records name before calling the handler.
+func (r *Recorder) StreamServerInterceptor(name string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		r.record(name)
+		return handler(srv, ss)
+	}
+}
+
+// UnaryClientInterceptor returns a new unary client interceptor that records name before invoking the call.
+func (r *Recorder) UnaryClientInterceptor(name string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		r.record(name)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a new stream client interceptor that records name before opening the stream.
+func (r *Recorder) StreamClientInterceptor(name string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		r.record(name)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// Order returns a copy of the recorded names, from the first entered interceptor.
+func (r *Recorder) Order() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+// Reset forgets the recorded names so that the Recorder can be reused for another call.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = nil
+}
+
+func (r *Recorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = append(r.names, name)
+}
@@ -0,0 +1,60 @@
+package middlewaretest
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestRecordOrder(t *testing.T) {
+	rec := RecordOrder()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor("server-outer"), rec.UnaryServerInterceptor("server-inner")),
+		grpc.ChainStreamInterceptor(rec.StreamServerInterceptor("server-outer"), rec.StreamServerInterceptor("server-inner")),
+	)
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(rec.UnaryClientInterceptor("client-outer"), rec.UnaryClientInterceptor("client-inner")),
+		grpc.WithChainStreamInterceptor(rec.StreamClientInterceptor("client-outer"), rec.StreamClientInterceptor("client-inner")),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	want := []string{"client-outer", "client-inner", "server-outer", "server-inner"}
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if got := rec.Order(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unary order = %v, want %v", got, want)
+	}
+
+	rec.Reset()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	// The first message is sent by the handler, once every interceptor has been entered.
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if got := rec.Order(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("stream order = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/internal/peeraddr"
+)
+
+// PeerAddr returns the address of the client of the incoming call, read from peer.FromContext,
+// empty when it is unknown. The logging interceptor logs it as the peer field.
+func PeerAddr(ctx context.Context) string {
+	return peeraddr.FromContext(ctx)
+}
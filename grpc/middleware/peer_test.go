@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestPeerAddr(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	if got := PeerAddr(ctx); got != "10.0.0.1:5000" {
+		t.Fatalf("PeerAddr = %q, want 10.0.0.1:5000", got)
+	}
+	if got := PeerAddr(context.Background()); got != "" {
+		t.Fatalf("PeerAddr without peer = %q, want empty", got)
+	}
+}
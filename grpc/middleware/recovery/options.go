@@ -0,0 +1,30 @@
+package recovery
+
+import "context"
+
+// Reporter is called with every recovered panic and the stack of the panicking goroutine,
+// e.g. to forward it to an error tracking service.
+type Reporter func(ctx context.Context, p interface{}, stack []byte)
+
+// Option configures the recovery interceptor.
+type Option func(*options)
+
+type options struct {
+	reporter Reporter
+}
+
+// WithReporter sets the Reporter called for every recovered panic, before the error is returned.
+// A panic of the reporter itself is recovered and logged.
+func WithReporter(reporter Reporter) Option {
+	return func(o *options) {
+		o.reporter = reporter
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,51 @@
+package recovery
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that recovers from panics in the handler.
+//
+// The panic is logged with its stack, passed to the Reporter if any, and converted to an `Internal` error
+// so that the server keeps running.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := logger.Stack()
+				fields := logger.Fields{
+					"panic":       r,
+					"stack":       stack,
+					"grpc.method": info.FullMethod,
+				}
+				if id, ok := requestid.FromContext(ctx); ok {
+					fields["request_id"] = id
+				}
+				logger.WithFields(fields).Error("recovered from panic")
+				if o.reporter != nil {
+					report(ctx, o.reporter, r, []byte(stack))
+				}
+				res, err = nil, status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// report calls reporter, logging instead of propagating its own panic.
+func report(ctx context.Context, reporter Reporter, p interface{}, stack []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.WithFields(logger.Fields{"panic": r}).Error("recovery reporter panicked")
+		}
+	}()
+	reporter(ctx, p, stack)
+}
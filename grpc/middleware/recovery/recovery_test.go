@@ -0,0 +1,75 @@
+package recovery
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+// panicking is a handler panicking with "boom".
+func panicking(context.Context, interface{}) (interface{}, error) {
+	panic("boom")
+}
+
+func TestReporter(t *testing.T) {
+	var (
+		reported interface{}
+		stack    []byte
+		id       string
+	)
+	interceptor := UnaryServerInterceptor(WithReporter(func(ctx context.Context, p interface{}, s []byte) {
+		reported, stack = p, s
+		id, _ = requestid.FromContext(ctx)
+	}))
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	logs.Entries(t)
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, panicking)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("error = %v, want code Internal", err)
+	}
+	if reported != "boom" {
+		t.Fatalf("reported panic = %v, want boom", reported)
+	}
+	if !strings.Contains(string(stack), "recovery.panicking") {
+		t.Fatalf("reported stack %q does not contain the panicking handler", stack)
+	}
+	if id != "req-1" {
+		t.Fatalf("request id of the reporter context = %q, want req-1", id)
+	}
+	if es := logs.Entries(t); len(es) != 1 || es[0]["panic"] != "boom" || es[0]["request_id"] != "req-1" {
+		t.Fatalf("entries %v, want the recovered panic", es)
+	}
+}
+
+func TestReporterPanic(t *testing.T) {
+	interceptor := UnaryServerInterceptor(WithReporter(func(context.Context, interface{}, []byte) {
+		panic("reporter down")
+	}))
+	logs.Entries(t)
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, panicking)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("error = %v, want code Internal", err)
+	}
+	es := logs.Entries(t)
+	if len(es) != 2 || es[1]["msg"] != "recovery reporter panicked" || es[1]["panic"] != "reporter down" {
+		t.Fatalf("entries %v, want the recovered panic and the reporter panic", es)
+	}
+}
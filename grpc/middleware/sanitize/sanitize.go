@@ -0,0 +1,41 @@
+package sanitize
+
+import (
+	"context"
+	"regexp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+)
+
+// Message is the status message replacing the scrubbed ones.
+const Message = "internal error"
+
+// UnaryServerInterceptor returns a new unary server interceptor that, in Production, replaces the status message
+// of returned errors matching any of patterns with "internal error", e.g. stack traces or SQL leaked by a handler.
+//
+// The code and the details of the status are preserved. In Development errors are returned untouched,
+// so it is meant as a last safety net, chained before grpcerror.
+func UnaryServerInterceptor(mode appmode.AppMode, patterns []*regexp.Regexp) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err == nil || mode != appmode.Production {
+			return res, err
+		}
+		return res, sanitize(err, patterns)
+	}
+}
+
+func sanitize(err error, patterns []*regexp.Regexp) error {
+	st := status.Convert(err)
+	for _, pattern := range patterns {
+		if pattern.MatchString(st.Message()) {
+			p := st.Proto()
+			p.Message = Message
+			return status.ErrorProto(p)
+		}
+	}
+	return err
+}
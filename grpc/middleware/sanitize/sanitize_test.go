@@ -0,0 +1,51 @@
+package sanitize
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+)
+
+var patterns = []*regexp.Regexp{regexp.MustCompile(`(?i)\bselect\b.*\bfrom\b`), regexp.MustCompile(`goroutine \d+ \[`)}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	leaked := status.New(codes.NotFound, "query failed: SELECT * FROM users WHERE id = 1")
+	leaked, err := leaked.WithDetails(wrapperspb.String("user_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		mode    appmode.AppMode
+		err     error
+		wantMsg string
+	}{
+		"production scrubs":          {mode: appmode.Production, err: leaked.Err(), wantMsg: Message},
+		"production keeps clean":     {mode: appmode.Production, err: status.Error(codes.NotFound, "user not found"), wantMsg: "user not found"},
+		"production scrubs non grpc": {mode: appmode.Production, err: errors.New("goroutine 7 [running]:"), wantMsg: Message},
+		"development untouched":      {mode: appmode.Development, err: leaked.Err(), wantMsg: "query failed: SELECT * FROM users WHERE id = 1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := UnaryServerInterceptor(tc.mode, patterns)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, func(context.Context, interface{}) (interface{}, error) {
+				return nil, tc.err
+			})
+			st := status.Convert(err)
+			if st.Message() != tc.wantMsg {
+				t.Fatalf("message = %q, want %q", st.Message(), tc.wantMsg)
+			}
+			if want := status.Code(tc.err); st.Code() != want {
+				t.Fatalf("code = %s, want %s", st.Code(), want)
+			}
+			if len(st.Details()) != len(status.Convert(tc.err).Details()) {
+				t.Fatalf("details = %v, want %v", st.Details(), status.Convert(tc.err).Details())
+			}
+		})
+	}
+}
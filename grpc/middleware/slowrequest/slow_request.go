@@ -0,0 +1,32 @@
+package slowrequest
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that logs a warning for calls slower than threshold.
+//
+// The warning carries the method, the measured duration and the request id. Faster calls are not logged.
+func UnaryServerInterceptor(threshold time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		if elapsed := time.Since(start); elapsed > threshold {
+			fields := logger.Fields{
+				"grpc.method":  info.FullMethod,
+				"grpc.time_ms": elapsed.Milliseconds(),
+			}
+			if id, ok := requestid.FromContext(ctx); ok {
+				fields["request_id"] = id
+			}
+			logger.WithFields(fields).Warnf("slow request, took more than %s", threshold)
+		}
+		return res, err
+	}
+}
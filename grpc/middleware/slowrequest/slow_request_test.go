@@ -0,0 +1,50 @@
+package slowrequest
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(20 * time.Millisecond)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	logs.Entries(t)
+
+	_, _ = interceptor(ctx, nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if es := logs.Entries(t); len(es) != 0 {
+		t.Fatalf("fast call logged %v", es)
+	}
+
+	_, _ = interceptor(ctx, nil, info, func(context.Context, interface{}) (interface{}, error) {
+		time.Sleep(40 * time.Millisecond)
+		return nil, nil
+	})
+	es := logs.Entries(t)
+	if len(es) != 1 {
+		t.Fatalf("got %d entries for the slow call, want 1", len(es))
+	}
+	if es[0]["level"] != "warn" || es[0]["grpc.method"] != "/test.Service/Call" || es[0]["request_id"] != "req-1" {
+		t.Fatalf("slow call entry = %v", es[0])
+	}
+	if ms, _ := es[0]["grpc.time_ms"].(float64); ms < 40 {
+		t.Fatalf("grpc.time_ms = %v, want at least 40", es[0]["grpc.time_ms"])
+	}
+}
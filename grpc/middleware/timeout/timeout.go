@@ -0,0 +1,70 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+type result struct {
+	res interface{}
+	err error
+	// panicked is set when the handler panicked with p, stack is the stack of the handler goroutine.
+	panicked bool
+	p        interface{}
+	stack    string
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that imposes a deadline of d on requests without one.
+//
+// When the deadline is exceeded, the handler's context is canceled and `DeadlineExceeded` is returned to the client
+// without waiting for the handler. Requests which already carry a deadline are left untouched.
+//
+// The handler runs in its own goroutine, its panics are raised again on the calling goroutine so that the recovery
+// interceptor, chained before, handles them. A panic after the deadline is only logged, the call having returned.
+func UnaryServerInterceptor(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		done := make(chan result, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					done <- result{panicked: true, p: p, stack: logger.Stack()}
+				}
+			}()
+			res, err := handler(ctx, req)
+			done <- result{res: res, err: err}
+		}()
+
+		select {
+		case r := <-done:
+			if r.panicked {
+				panic(r.p)
+			}
+			return r.res, r.err
+		case <-ctx.Done():
+			go logLatePanic(done, info.FullMethod)
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// logLatePanic waits for the handler abandoned at the deadline and logs its panic, if any.
+func logLatePanic(done <-chan result, method string) {
+	if r := <-done; r.panicked {
+		logger.WithFields(logger.Fields{
+			"panic":       r.p,
+			"stack":       r.stack,
+			"grpc.method": method,
+		}).Error("recovered from panic after deadline")
+	}
+}
@@ -0,0 +1,93 @@
+package timeout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/grpc/middleware/recovery"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+func TestUnaryServerInterceptorDeadlineExceeded(t *testing.T) {
+	interceptor := UnaryServerInterceptor(20 * time.Millisecond)
+	canceled := make(chan error, 1)
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+		case <-time.After(time.Second):
+			canceled <- nil
+		}
+		return "late", nil
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("code = %v, want DeadlineExceeded", status.Code(err))
+	}
+	if err := <-canceled; err != context.DeadlineExceeded {
+		t.Fatalf("handler context error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestUnaryServerInterceptorFastHandler(t *testing.T) {
+	interceptor := UnaryServerInterceptor(time.Second)
+	res, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("handler context has no deadline")
+		}
+		return "ok", nil
+	})
+	if err != nil || res != "ok" {
+		t.Fatalf("got (%v, %v), want (ok, nil)", res, err)
+	}
+}
+
+func TestUnaryServerInterceptorKeepsExistingDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	interceptor := UnaryServerInterceptor(time.Millisecond)
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if got, _ := ctx.Deadline(); !got.Equal(want) {
+			t.Errorf("deadline = %v, want %v", got, want)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnaryServerInterceptorPanicReachesRecovery(t *testing.T) {
+	rec := recovery.UnaryServerInterceptor()
+	interceptor := UnaryServerInterceptor(time.Second)
+	_, err := rec(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %v, want Internal", status.Code(err))
+	}
+}
+
+func TestUnaryServerInterceptorPanicAfterDeadline(t *testing.T) {
+	interceptor := UnaryServerInterceptor(10 * time.Millisecond)
+	panicked := make(chan struct{})
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		defer close(panicked)
+		panic("late boom")
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("code = %v, want DeadlineExceeded", status.Code(err))
+	}
+	<-panicked
+	// The process is still alive: the late panic was recovered and logged.
+	time.Sleep(10 * time.Millisecond)
+}
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeRequest struct {
+	err error
+}
+
+func (r fakeRequest) Validate() error {
+	return r.err
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	for name, tc := range map[string]struct {
+		req         interface{}
+		wantCode    codes.Code
+		wantMsg     string
+		wantHandled bool
+	}{
+		"validation fails":  {req: fakeRequest{err: errors.New("invalid Request.Name: value length must be at least 1 runes")}, wantCode: codes.InvalidArgument, wantMsg: "invalid Request.Name: value length must be at least 1 runes"},
+		"validation passes": {req: fakeRequest{}, wantCode: codes.OK, wantHandled: true},
+		"no validator":      {req: "plain request", wantCode: codes.OK, wantHandled: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handled := false
+			_, err := interceptor(context.Background(), tc.req, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, func(context.Context, interface{}) (interface{}, error) {
+				handled = true
+				return nil, nil
+			})
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("error = %v, want %s", err, tc.wantCode)
+			}
+			if msg := status.Convert(err).Message(); err != nil && msg != tc.wantMsg {
+				t.Fatalf("message = %q, want %q", msg, tc.wantMsg)
+			}
+			if handled != tc.wantHandled {
+				t.Fatalf("handler called = %v, want %v", handled, tc.wantHandled)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// RegisterHealth registers the standard health service on s and returns its controller, so that the
+// application can flip the serving status. The overall status ("" service) starts as SERVING.
+func RegisterHealth(s *grpc.Server) *health.Server {
+	h := health.NewServer()
+	healthpb.RegisterHealthServer(s, h)
+	return h
+}
+
+// SetServing marks services as SERVING, the overall status when no service is given.
+func SetServing(h *health.Server, services ...string) {
+	setStatus(h, healthpb.HealthCheckResponse_SERVING, services)
+}
+
+// SetNotServing marks services as NOT_SERVING, the overall status when no service is given.
+func SetNotServing(h *health.Server, services ...string) {
+	setStatus(h, healthpb.HealthCheckResponse_NOT_SERVING, services)
+}
+
+func setStatus(h *health.Server, status healthpb.HealthCheckResponse_ServingStatus, services []string) {
+	if len(services) == 0 {
+		services = []string{""}
+	}
+	for _, service := range services {
+		h.SetServingStatus(service, status)
+	}
+}
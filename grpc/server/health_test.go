@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialBufconn serves s over an in-memory listener and returns a connection to it.
+func dialBufconn(t *testing.T, s *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRegisterHealthTransitions(t *testing.T) {
+	s := grpc.NewServer()
+	h := RegisterHealth(s)
+	SetServing(h, "billing")
+	client := healthpb.NewHealthClient(dialBufconn(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	next := func() healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		res, err := watch.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		return res.GetStatus()
+	}
+
+	if got := next(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("initial status = %v, want SERVING", got)
+	}
+	SetNotServing(h)
+	if got := next(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v, want NOT_SERVING", got)
+	}
+	SetServing(h)
+	if got := next(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v, want SERVING", got)
+	}
+
+	// Named services are toggled independently of the overall status.
+	SetNotServing(h, "billing")
+	res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "billing"})
+	if err != nil || res.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Check(billing) = (%v, %v), want NOT_SERVING", res, err)
+	}
+	res, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check() = (%v, %v), want SERVING", res, err)
+	}
+}
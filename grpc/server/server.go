@@ -0,0 +1,22 @@
+package server
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+	"github.com/linhbkhn95/golang-british/grpc/middleware"
+)
+
+// New returns a GRPC server with the default unary interceptors installed, see middleware.DefaultUnaryChain:
+// request-id, recovery, logging and grpcerror, from the outer most. grpcerror runs in development mode when mode
+// is Development, in Production unexpected errors are replaced by internalErr, a generic `Internal` error when nil.
+// opts are applied after, interceptors they chain run inside the default ones.
+// Example:
+//
+//	s := server.New(appmode.Production, nil)
+//	examplev1.RegisterExampleServiceServer(s, impl)
+//	err := s.Serve(lis)
+func New(mode appmode.AppMode, internalErr error, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{middleware.DefaultUnaryChain(mode == appmode.Development, internalErr)}, opts...)
+	return grpc.NewServer(opts...)
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/middlewaretest"
+	"github.com/linhbkhn95/golang-british/grpc/middleware/requestid"
+)
+
+// logs records the JSON lines of the global logger.
+var logs *middlewaretest.Logs
+
+func TestMain(m *testing.M) {
+	logs = middlewaretest.CaptureLogs()
+	os.Exit(m.Run())
+}
+
+// behaviorService fails or panics according to the payload of the call, and echoes it otherwise.
+type behaviorService struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (behaviorService) UnaryCall(_ context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	switch body := string(req.GetPayload().GetBody()); body {
+	case "panic":
+		panic("boom")
+	case "not found":
+		return nil, status.Error(codes.NotFound, "user not found")
+	case "unexpected":
+		return nil, errors.New("connection reset by peer")
+	default:
+		return &testpb.SimpleResponse{Payload: req.GetPayload()}, nil
+	}
+}
+
+func TestNew(t *testing.T) {
+	internalErr := status.Error(codes.Internal, "something went wrong")
+	for name, tc := range map[string]struct {
+		mode appmode.AppMode
+		want map[string]*status.Status
+	}{
+		"production": {mode: appmode.Production, want: map[string]*status.Status{
+			"panic":      status.New(codes.Internal, "internal error"),
+			"not found":  status.New(codes.NotFound, "user not found"),
+			"unexpected": status.Convert(internalErr),
+		}},
+		"development": {mode: appmode.Development, want: map[string]*status.Status{
+			"panic":      status.New(codes.Internal, "internal error"),
+			"not found":  status.New(codes.NotFound, "rpc error: code = NotFound desc = user not found"),
+			"unexpected": status.New(codes.Unknown, "connection reset by peer"),
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := New(tc.mode, internalErr)
+			testpb.RegisterTestServiceServer(s, behaviorService{})
+			client := testpb.NewTestServiceClient(dialBufconn(t, s))
+			logs.Entries(t)
+
+			for body, want := range tc.want {
+				_, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: []byte(body)}})
+				if got := status.Convert(err); got.Code() != want.Code() || got.Message() != want.Message() {
+					t.Errorf("%s: error = %v, want %v", body, err, want.Err())
+				}
+			}
+
+			// The server keeps serving after a panic, and the request id is sent back.
+			var header metadata.MD
+			res, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{Payload: &testpb.Payload{Body: []byte("hi")}}, grpc.Header(&header))
+			if err != nil || string(res.GetPayload().GetBody()) != "hi" {
+				t.Fatalf("UnaryCall = (%v, %v), want the echo", res, err)
+			}
+			if len(header.Get(requestid.MetadataKey)) != 1 {
+				t.Errorf("header %v, want a request id", header)
+			}
+			var recovered bool
+			for _, entry := range logs.Entries(t) {
+				if entry["msg"] == "recovered from panic" && entry["request_id"] != nil {
+					recovered = true
+				}
+			}
+			if !recovered {
+				t.Error("recovered panic not logged with its request id")
+			}
+		})
+	}
+}
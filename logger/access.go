@@ -0,0 +1,42 @@
+package logger
+
+// AccessEntry describes one served HTTP or GRPC request.
+type AccessEntry struct {
+	Method     string
+	Path       string
+	StatusCode int
+	LatencyMS  int64
+	BytesOut   int64
+	RemoteAddr string
+}
+
+// AccessLogger writes access logs, kept apart from the application logs so that they can be routed separately.
+type AccessLogger interface {
+	Log(entry AccessEntry)
+}
+
+type accessLogger struct {
+	logger Logger
+}
+
+// NewAccessLogger returns an AccessLogger writing through a zap logger built from cfg.
+// Every entry is logged at info level with the message "access" and the fields
+// method, path, status_code, latency_ms, bytes_out and remote_addr.
+func NewAccessLogger(cfg Configuration) (AccessLogger, error) {
+	l, err := NewLogger(cfg, LoggerBackendZap)
+	if err != nil {
+		return nil, err
+	}
+	return &accessLogger{logger: l}, nil
+}
+
+func (l *accessLogger) Log(entry AccessEntry) {
+	l.logger.WithFields(Fields{
+		"method":      entry.Method,
+		"path":        entry.Path,
+		"status_code": entry.StatusCode,
+		"latency_ms":  entry.LatencyMS,
+		"bytes_out":   entry.BytesOut,
+		"remote_addr": entry.RemoteAddr,
+	}).Info("access")
+}
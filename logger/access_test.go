@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAccessLoggerKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := NewAccessLogger(Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: buf})
+	if err != nil {
+		t.Fatalf("NewAccessLogger: %v", err)
+	}
+	l.Log(AccessEntry{Method: "GET", Path: "/users", StatusCode: 200, LatencyMS: 12, BytesOut: 512, RemoteAddr: "10.0.0.1:5000"})
+
+	es := entries(t, buf)
+	if len(es) != 1 {
+		t.Fatalf("got %d entries, want 1", len(es))
+	}
+	keys := make([]string, 0, len(es[0]))
+	for k := range es[0] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	want := []string{"bytes_out", "caller", "latency_ms", "level", "method", "msg", "path", "remote_addr", "status_code", "ts"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	assertFields(t, es[0], map[string]interface{}{
+		"msg":         "access",
+		"method":      "GET",
+		"path":        "/users",
+		"status_code": float64(200),
+		"latency_ms":  float64(12),
+		"bytes_out":   float64(512),
+		"remote_addr": "10.0.0.1:5000",
+	})
+}
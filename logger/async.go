@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultAsyncBufferSize is the number of entries buffered when Configuration.AsyncBufferSize is not set.
+	defaultAsyncBufferSize = 1024
+	// asyncDropWarnInterval is the minimum interval between two warnings about dropped entries.
+	asyncDropWarnInterval = time.Second
+)
+
+type asyncItem struct {
+	data    []byte
+	flushed chan struct{}
+}
+
+// asyncWriter writes to the underlying writer from a background goroutine, until it is closed.
+// Entries are dropped rather than blocking the caller when the buffer is full, a warning reporting
+// the number of dropped entries is then written periodically to warnOutput.
+// Entries written after Close are dropped.
+type asyncWriter struct {
+	out       io.Writer
+	warn      io.Writer
+	queue     chan asyncItem
+	dropped   uint64
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func newAsyncWriter(out io.Writer, bufferSize int) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	w := &asyncWriter{
+		out:     out,
+		warn:    warnOutput,
+		queue:   make(chan asyncItem, bufferSize),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues a copy of p, the caller may reuse p once Write returns.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	select {
+	case w.queue <- asyncItem{data: data}:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Sync blocks until every entry queued before the call has been written, then syncs the underlying writer.
+func (w *asyncWriter) Sync() error {
+	flushed := make(chan struct{})
+	select {
+	case w.queue <- asyncItem{flushed: flushed}:
+		select {
+		case <-flushed:
+		case <-w.stopped:
+		}
+	case <-w.stopped:
+	}
+	if s, ok := w.out.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close writes the queued entries, then stops the background goroutine and waits for it to exit.
+// The underlying writer is left open.
+func (w *asyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		<-w.stopped
+	})
+	return nil
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(asyncDropWarnInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case item := <-w.queue:
+			w.handle(item)
+		case <-ticker.C:
+			w.warnDropped()
+		case <-w.done:
+			for {
+				select {
+				case item := <-w.queue:
+					w.handle(item)
+				default:
+					w.warnDropped()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *asyncWriter) handle(item asyncItem) {
+	if item.flushed != nil {
+		w.warnDropped()
+		close(item.flushed)
+		return
+	}
+	_, _ = w.out.Write(item.data)
+}
+
+func (w *asyncWriter) warnDropped() {
+	if n := atomic.SwapUint64(&w.dropped, 0); n > 0 {
+		_, _ = fmt.Fprintf(w.warn, "logger: dropped %d entries, async buffer is full\n", n)
+	}
+}
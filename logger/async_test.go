@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every write until release is closed.
+type blockingWriter struct {
+	lockedBuffer
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.lockedBuffer.Write(p)
+}
+
+func TestAsyncWriterEventuallyWrites(t *testing.T) {
+	out := &lockedBuffer{}
+	w := newAsyncWriter(out, 16)
+	_, _ = w.Write([]byte("line\n"))
+	deadline := time.Now().Add(time.Second)
+	for !bytes.Contains(out.bytes(), []byte("line")) {
+		if time.Now().After(deadline) {
+			t.Fatal("queued line never written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncWriterSyncDrains(t *testing.T) {
+	out := &lockedBuffer{}
+	w := newAsyncWriter(out, 1024)
+	for i := 0; i < 100; i++ {
+		_, _ = w.Write([]byte("line\n"))
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if n := bytes.Count(out.bytes(), []byte("line\n")); n != 100 {
+		t.Fatalf("%d lines written before Sync returned, want 100", n)
+	}
+}
+
+// captureWarnings records the warnings written to warnOutput during the test.
+func captureWarnings(t *testing.T) *lockedBuffer {
+	t.Helper()
+	warnings := &lockedBuffer{}
+	warnOutput = warnings
+	t.Cleanup(func() { warnOutput = os.Stderr })
+	return warnings
+}
+
+func TestAsyncWriterDropsWhenFull(t *testing.T) {
+	warnings := captureWarnings(t)
+	out := &blockingWriter{release: make(chan struct{})}
+	w := newAsyncWriter(out, 2)
+	defer w.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			_, _ = w.Write([]byte("line\n"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Write blocked on a full buffer")
+	}
+	close(out.release)
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	written := bytes.Count(out.bytes(), []byte("line\n"))
+	if written >= 10 {
+		t.Fatalf("%d lines written, want some dropped", written)
+	}
+	if strings.Contains(string(out.bytes()), "logger: dropped") {
+		t.Fatalf("output %q contains the warning about the dropped entries", out.bytes())
+	}
+	if !strings.Contains(string(warnings.bytes()), "logger: dropped") {
+		t.Fatalf("warnings %q do not report the dropped entries", warnings.bytes())
+	}
+}
+
+func TestAsyncWriterCloseDrains(t *testing.T) {
+	out := &lockedBuffer{}
+	w := newAsyncWriter(out, 1024)
+	for i := 0; i < 100; i++ {
+		_, _ = w.Write([]byte("line\n"))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n := bytes.Count(out.bytes(), []byte("line\n")); n != 100 {
+		t.Fatalf("%d lines written before Close returned, want 100", n)
+	}
+	select {
+	case <-w.stopped:
+	default:
+		t.Fatal("background goroutine still running after Close")
+	}
+	// Neither a second Close nor Sync blocks once the writer is closed.
+	_ = w.Close()
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync after Close: %v", err)
+	}
+}
+
+func TestAsyncLoggerCloseStopsWriters(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			out := &lockedBuffer{}
+			for i := 0; i < 10; i++ {
+				l, err := NewLogger(Configuration{
+					EnableConsole:     true,
+					ConsoleJSONFormat: true,
+					ConsoleLevel:      infoLvl,
+					ConsoleWriter:     out,
+					EnableFile:        true,
+					FileLevel:         infoLvl,
+					FileWriter:        io.Discard,
+					Async:             true,
+				}, backend)
+				if err != nil {
+					t.Fatalf("NewLogger: %v", err)
+				}
+				l.Info("async line")
+				if err := l.Close(); err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+			}
+			if n := strings.Count(string(out.bytes()), "async line"); n != 10 {
+				t.Fatalf("%d lines written before Close returned, want 10", n)
+			}
+			if after := runtime.NumGoroutine(); after > before {
+				t.Fatalf("%d goroutines after closing the loggers, %d before", after, before)
+			}
+		})
+	}
+}
+
+func TestAsyncLoggerSync(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			out := &lockedBuffer{}
+			l, err := NewLogger(Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: out, Async: true}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			for i := 0; i < 50; i++ {
+				l.Info("async line")
+			}
+			if err := l.Sync(); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if n := strings.Count(string(out.bytes()), "async line"); n != 50 {
+				t.Fatalf("%d lines written before Sync returned, want 50", n)
+			}
+		})
+	}
+}
+
+func TestAsyncLoggerFatalFlushesBeforeExit(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			out := &lockedBuffer{}
+			l, err := NewLogger(Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: out, Async: true}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			var written bool
+			SetExitFunc(func(int) { written = strings.Contains(string(out.bytes()), "fatal line") })
+			t.Cleanup(func() { SetExitFunc(os.Exit) })
+
+			l.Fatal("fatal line")
+			if !written {
+				t.Fatal("fatal entry not flushed before exit")
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package logger
+
+// NewAudit returns a Logger writing JSON entries to cfg.FileLocation only, independently of the global logger,
+// e.g. for audit events which must be kept apart from the application logs.
+//
+// The file settings of cfg are used, the rest is forced: the file level is info, there is no console nor syslog
+// output, NewAudit fails rather than falling back to the console when the file is not writable, and writes are
+// synchronous so that no entry is ever dropped. The file is rotated as usual but the rotated files are kept
+// forever, FileMaxAgeDays and FileRetentionInterval are ignored.
+func NewAudit(cfg Configuration) (Logger, error) {
+	cfg.EnableConsole = false
+	cfg.EnableSyslog = false
+	cfg.EnableFile = true
+	cfg.FileJSONFormat = true
+	cfg.FileLevel = infoLvl
+	cfg.ConsoleLevel = ""
+	cfg.ConsoleLevelTyped = ""
+	cfg.StrictFile = true
+	cfg.Async = false
+	cfg.FileMaxAgeDays = -1
+	cfg.FileRetentionInterval = 0
+	return NewLogger(cfg, LoggerBackendZap)
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+func TestNewAudit(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "audit.log")
+	var console bytes.Buffer
+	l, err := NewAudit(Configuration{
+		EnableConsole:         true,
+		ConsoleLevel:          debugLvl,
+		ConsoleWriter:         &console,
+		FileLocation:          location,
+		FileLevel:             debugLvl,
+		FileMaxAgeDays:        1,
+		FileRetentionInterval: time.Hour,
+		Async:                 true,
+	})
+	if err != nil {
+		t.Fatalf("NewAudit: %v", err)
+	}
+	l.Debug("dropped")
+	l.WithFields(Fields{"user": "alice"}).Info("login")
+
+	closers := l.(*zapLogger).closers
+	if len(closers) != 1 {
+		t.Fatalf("got %d closers, want the file only, without retention", len(closers))
+	}
+	if handler := closers[0].(*lumberjack.Logger); handler.MaxAge != 0 {
+		t.Errorf("rotated files kept %d days, want forever", handler.MaxAge)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	b, err := os.ReadFile(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("audit file has %d lines, want 1: %s", len(lines), b)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("audit entry is not JSON: %v", err)
+	}
+	for _, key := range []string{"level", "ts", "caller", "msg", "user"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("entry %v has no %q", entry, key)
+		}
+	}
+	if entry["msg"] != "login" || entry["user"] != "alice" || entry["level"] != "info" {
+		t.Errorf("entry = %v", entry)
+	}
+	if console.Len() != 0 {
+		t.Errorf("audit entries written to the console: %s", console.String())
+	}
+}
+
+func TestNewAuditRequiresWritableFile(t *testing.T) {
+	dir := t.TempDir()
+	// A directory cannot be opened as the log file.
+	if _, err := NewAudit(Configuration{FileLocation: dir}); err == nil {
+		t.Fatal("NewAudit succeeded on an unwritable location")
+	}
+	if _, err := NewAudit(Configuration{}); err == nil {
+		t.Fatal("NewAudit succeeded without file location")
+	}
+}
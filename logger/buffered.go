@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// bufferedWriter buffers writes to the underlying writer, the buffer being flushed when full and on Sync.
+type bufferedWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func newBufferedWriter(out io.Writer, size int) *bufferedWriter {
+	return &bufferedWriter{w: bufio.NewWriterSize(out, size)}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.w.Write(p)
+}
+
+// Sync flushes the buffer to the underlying writer.
+func (b *bufferedWriter) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.w.Flush()
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBufferedFileFlushedOnSync(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l, err := NewLogger(Configuration{EnableFile: true, FileJSONFormat: true, FileLevel: infoLvl, FileLocation: path, FileBufferSize: 64 << 10}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			defer l.Close()
+			read := func() string {
+				content, err := os.ReadFile(path)
+				if err != nil && !os.IsNotExist(err) {
+					t.Fatal(err)
+				}
+				return string(content)
+			}
+
+			l.Info("buffered line")
+			if content := read(); content != "" {
+				t.Fatalf("file %q written before Sync", content)
+			}
+			if err := l.Sync(); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if content := read(); !strings.Contains(content, "buffered line") {
+				t.Fatalf("file %q does not contain the entry after Sync", content)
+			}
+		})
+	}
+}
+
+func benchmarkFileLogger(b *testing.B, bufferSize int) {
+	for name, backend := range backends {
+		b.Run(name, func(b *testing.B) {
+			l, err := NewLogger(Configuration{
+				EnableFile:     true,
+				FileJSONFormat: true,
+				FileLevel:      infoLvl,
+				FileLocation:   filepath.Join(b.TempDir(), "app.log"),
+				FileBufferSize: bufferSize,
+			}, backend)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer l.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("benchmark line")
+			}
+			if err := l.Sync(); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
+
+func BenchmarkFileUnbuffered(b *testing.B) {
+	benchmarkFileLogger(b, 0)
+}
+
+func BenchmarkFileBuffered(b *testing.B) {
+	benchmarkFileLogger(b, 64<<10)
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// now returns the time of the entries, for both backends.
+var now = time.Now
+
+// SetClock replaces the function returning the time of the entries, time.Now by default, nil restores it.
+// It is meant for tests which need deterministic timestamps, it must not be called while logging.
+func SetClock(fn func() time.Time) {
+	if fn == nil {
+		fn = time.Now
+	}
+	now = fn
+}
+
+// zapClock is the zapcore.Clock reading now on every entry, so that SetClock applies to existing loggers.
+type zapClock struct{}
+
+func (zapClock) Now() time.Time {
+	return now()
+}
+
+func (zapClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+// clockHook sets the time of logrus entries from now, logrus always uses time.Now.
+type clockHook struct{}
+
+func (clockHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (clockHook) Fire(entry *logrus.Entry) error {
+	entry.Time = now()
+	return nil
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetClock(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2024, 3, 1, 10, 20, 30, 500e6, time.UTC) })
+	t.Cleanup(func() { SetClock(nil) })
+	// Each backend keeps its own time key and format.
+	want := map[string]map[string]interface{}{
+		"zap":    {"ts": "2024-03-01T10:20:30.500Z"},
+		"logrus": {"time": "2024-03-01T10:20:30Z"},
+	}
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{ConsoleLevel: infoLvl})
+			// The clock is read on every entry, so it applies to loggers created before SetClock too.
+			assertFields(t, lastEntry(t, l, buf), want[name])
+		})
+	}
+}
+
+func TestSetClockNilRestoresTimeNow(t *testing.T) {
+	SetClock(func() time.Time { return time.Time{} })
+	SetClock(nil)
+	before := time.Now()
+	if got := now(); got.Before(before) || got.Sub(before) > time.Minute {
+		t.Fatalf("now() = %v after SetClock(nil), want the current time", got)
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openDescriptors returns the number of descriptors of the process open on path, it skips the test without /proc.
+func openDescriptors(t *testing.T, path string) int {
+	t.Helper()
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("list open descriptors: %v", err)
+	}
+	n := 0
+	for _, fd := range fds {
+		if target, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name())); err == nil && target == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCloseReleasesFile(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l, err := NewLogger(Configuration{EnableFile: true, FileLevel: infoLvl, FileLocation: path}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.Info("line")
+			if openDescriptors(t, path) == 0 {
+				t.Fatal("log file is not open")
+			}
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if n := openDescriptors(t, path); n != 0 {
+				t.Fatalf("%d descriptors still open on the log file after Close", n)
+			}
+		})
+	}
+}
+
+func TestCloseConsoleOnly(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, _ := newBufferLogger(t, backend, Configuration{})
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type fieldsCtxKey struct{}
+
+type loggerCtxKey struct{}
+
+// ContextExtractor returns the fields to log for ctx, nil when there is none.
+type ContextExtractor func(ctx context.Context) Fields
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// ContextWithFields returns a copy of ctx carrying fields on top of the ones already stored in ctx.
+// Later keys override earlier keys with the same name, fields stored in ctx are never mutated.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	existing := FieldsFromContext(ctx)
+	merged := make(Fields, len(existing)+len(fields))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsCtxKey{}, merged)
+}
+
+// FieldsFromContext returns the fields stored in ctx, nil when there is none.
+// The returned map must not be modified.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(fieldsCtxKey{}).(Fields)
+	return fields
+}
+
+// ToContext returns a copy of ctx carrying l, e.g. a request-scoped logger built by a middleware.
+func ToContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by ToContext, the global logger when there is none.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerCtxKey{}).(Logger); ok {
+		return l
+	}
+	return global()
+}
+
+// RegisterContextExtractor adds an extractor whose fields are attached by WithContext.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, extractor)
+}
+
+// WithContext returns the logger of ctx, see FromContext, carrying the fields stored in ctx and the ones
+// of the registered extractors.
+func WithContext(ctx context.Context) Logger {
+	fields := Fields{}
+	extractorsMu.RLock()
+	for _, extract := range extractors {
+		for k, v := range extract(ctx) {
+			fields[k] = v
+		}
+	}
+	extractorsMu.RUnlock()
+	for k, v := range FieldsFromContext(ctx) {
+		fields[k] = v
+	}
+	l := FromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.WithFields(fields)
+}
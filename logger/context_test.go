@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestContextWithFieldsAccumulates(t *testing.T) {
+	parent := ContextWithFields(context.Background(), Fields{"request_id": "r1", "user": "alice"})
+	child := ContextWithFields(parent, Fields{"user": "bob", "tenant": "acme"})
+
+	if got, want := FieldsFromContext(parent), (Fields{"request_id": "r1", "user": "alice"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("parent fields = %v, want %v", got, want)
+	}
+	if got, want := FieldsFromContext(child), (Fields{"request_id": "r1", "user": "bob", "tenant": "acme"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("child fields = %v, want %v", got, want)
+	}
+}
+
+func TestFieldsFromEmptyContext(t *testing.T) {
+	if fields := FieldsFromContext(context.Background()); fields != nil {
+		t.Fatalf("FieldsFromContext = %v, want nil", fields)
+	}
+}
+
+func TestWithContextLogsContextFields(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			ctx := ToContext(context.Background(), l)
+			ctx = ContextWithFields(ctx, Fields{"request_id": "r1"})
+			assertFields(t, lastEntry(t, WithContext(ctx), buf), map[string]interface{}{"request_id": "r1"})
+		})
+	}
+}
+
+func TestToContextAndFromContext(t *testing.T) {
+	resetGlobal(t)
+	if l := FromContext(context.Background()); l != log {
+		t.Fatalf("FromContext without logger = %v, want the global logger", l)
+	}
+	l := NewJSONCapture()
+	if got := FromContext(ToContext(context.Background(), l)); got != Logger(l) {
+		t.Fatalf("FromContext = %v, want the stored logger", got)
+	}
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"io"
+	"testing"
+)
+
+func TestDelegates(t *testing.T) {
+	cfg := Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: io.Discard}
+	zl, err := NewLogger(cfg, LoggerBackendZap)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	ll, err := NewLogger(cfg, LoggerBackendLogrus)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+
+	for name, l := range map[string]Logger{"zap": zl, "zap with fields": zl.WithFields(Fields{"k": 1})} {
+		if d, ok := ZapDelegate(l); !ok || d == nil {
+			t.Errorf("ZapDelegate(%s) = (%v, %v), want the zap logger", name, d, ok)
+		}
+		if d, ok := LogrusDelegate(l); ok || d != nil {
+			t.Errorf("LogrusDelegate(%s) = (%v, %v), want false", name, d, ok)
+		}
+	}
+	for name, l := range map[string]Logger{"logrus": ll, "logrus with fields": ll.WithFields(Fields{"k": 1})} {
+		if d, ok := LogrusDelegate(l); !ok || d == nil {
+			t.Errorf("LogrusDelegate(%s) = (%v, %v), want the logrus logger", name, d, ok)
+		}
+		if d, ok := ZapDelegate(l); ok || d != nil {
+			t.Errorf("ZapDelegate(%s) = (%v, %v), want false", name, d, ok)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ConfigFromEnv returns a Configuration read from the environment variables named by the `env` tags of its fields,
+// e.g. LOG_ENABLE_CONSOLE or LOG_FILE_LOCATION. Unset or empty variables fall back to the `default` tag. So do
+// invalid values, i.e. booleans strconv.ParseBool rejects and values missing from the `enum` tag such as an
+// unknown level, with a warning on stderr. Fields without `env` tag keep their zero value.
+func ConfigFromEnv() Configuration {
+	var cfg Configuration
+	v := reflect.ValueOf(&cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		def := field.Tag.Get("default")
+		value := os.Getenv(key)
+		if value == "" {
+			value = def
+		}
+		if !setFromString(v.Field(i), value, field.Tag.Get("enum")) {
+			_, _ = fmt.Fprintf(warnOutput, "logger: invalid %s %q, using the default %q\n", key, value, def)
+			setFromString(v.Field(i), def, "")
+		}
+	}
+	return cfg
+}
+
+// setFromString sets f from value and reports whether value is valid, one of the comma separated enum if not empty.
+// Only encoding.TextUnmarshaler, string and bool fields are supported.
+func setFromString(f reflect.Value, value, enum string) bool {
+	if enum != "" && !inEnum(value, enum) {
+		return false
+	}
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value)) == nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		f.SetBool(b)
+	}
+	return true
+}
+
+func inEnum(value, enum string) bool {
+	for _, v := range strings.Split(enum, ",") {
+		if strings.TrimSpace(v) == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var envKeys = []string{
+	"LOG_ENABLE_CONSOLE", "LOG_CONSOLE_JSON_FORMAT", "LOG_CONSOLE_LEVEL", "LOG_ENABLE_FILE",
+	"LOG_FILE_JSON_FORMAT", "LOG_FILE_LEVEL", "LOG_FILE_LOCATION", "LOG_FATAL_PANICS",
+}
+
+// unsetEnv unsets the variables read by ConfigFromEnv for the duration of the test.
+func unsetEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range envKeys {
+		t.Setenv(key, "")
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConfigFromEnvDefaults(t *testing.T) {
+	unsetEnv(t)
+	want := Configuration{EnableConsole: true, ConsoleLevel: infoLvl, FileLevel: infoLvl}
+	if got := ConfigFromEnv(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ConfigFromEnv = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	unsetEnv(t)
+	t.Setenv("LOG_ENABLE_CONSOLE", "false")
+	t.Setenv("LOG_CONSOLE_JSON_FORMAT", "true")
+	t.Setenv("LOG_CONSOLE_LEVEL", "debug")
+	t.Setenv("LOG_ENABLE_FILE", "1")
+	t.Setenv("LOG_FILE_JSON_FORMAT", "true")
+	t.Setenv("LOG_FILE_LEVEL", "error")
+	t.Setenv("LOG_FILE_LOCATION", "/var/log/app.log")
+	t.Setenv("LOG_FATAL_PANICS", "true")
+	want := Configuration{
+		ConsoleJSONFormat: true,
+		ConsoleLevel:      debugLvl,
+		EnableFile:        true,
+		FileJSONFormat:    true,
+		FileLevel:         errorLvl,
+		FileLocation:      "/var/log/app.log",
+		FatalPanics:       true,
+	}
+	if got := ConfigFromEnv(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ConfigFromEnv = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigFromEnvInvalidValuesFallBack(t *testing.T) {
+	unsetEnv(t)
+	warnings := captureWarnings(t)
+	t.Setenv("LOG_ENABLE_CONSOLE", "sometimes")
+	t.Setenv("LOG_CONSOLE_LEVEL", "bogus")
+	t.Setenv("LOG_FILE_LEVEL", "INFO")
+	want := Configuration{EnableConsole: true, ConsoleLevel: infoLvl, FileLevel: infoLvl}
+	if got := ConfigFromEnv(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ConfigFromEnv = %+v, want the defaults %+v", got, want)
+	}
+	for _, want := range []string{
+		`logger: invalid LOG_ENABLE_CONSOLE "sometimes", using the default "true"`,
+		`logger: invalid LOG_CONSOLE_LEVEL "bogus", using the default "info"`,
+		`logger: invalid LOG_FILE_LEVEL "INFO", using the default "info"`,
+	} {
+		if !strings.Contains(string(warnings.bytes()), want) {
+			t.Errorf("warnings %q do not contain %q", warnings.bytes(), want)
+		}
+	}
+}
+
+func TestConfigFromEnvEmptyIsUnset(t *testing.T) {
+	unsetEnv(t)
+	warnings := captureWarnings(t)
+	t.Setenv("LOG_CONSOLE_LEVEL", "")
+	if got := ConfigFromEnv().ConsoleLevel; got != infoLvl {
+		t.Fatalf("ConsoleLevel = %q, want the default info", got)
+	}
+	if len(warnings.bytes()) != 0 {
+		t.Fatalf("warnings %q for an empty variable", warnings.bytes())
+	}
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+const defaultErrorKey = "error"
+
+var errorKey atomic.Value
+
+// SetErrorKey sets the key under which errors are logged by WithError, Err and the GRPC middlewares,
+// "error" by default. An empty key restores the default.
+func SetErrorKey(key string) {
+	if key == "" {
+		key = defaultErrorKey
+	}
+	errorKey.Store(key)
+}
+
+// ErrorKey returns the key under which errors are logged.
+func ErrorKey() string {
+	if key, ok := errorKey.Load().(string); ok {
+		return key
+	}
+	return defaultErrorKey
+}
+
+type fieldType uint8
+
+const (
+	anyField fieldType = iota
+	stringField
+	intField
+	int64Field
+	float64Field
+	boolField
+	durationField
+	byteSizeField
+)
+
+// Field is a single key/value pair, built by the typed constructors such as String or Duration.
+// Scalar values are stored unboxed, so that zap encodes them without allocating.
+type Field struct {
+	Key   string
+	typ   fieldType
+	num   int64
+	str   string
+	iface interface{}
+}
+
+// Value returns the value of f, as passed to its constructor.
+func (f Field) Value() interface{} {
+	switch f.typ {
+	case stringField:
+		return f.str
+	case intField:
+		return int(f.num)
+	case int64Field:
+		return f.num
+	case float64Field:
+		return math.Float64frombits(uint64(f.num))
+	case boolField:
+		return f.num != 0
+	case durationField:
+		return time.Duration(f.num)
+	case byteSizeField:
+		return ByteSize(f.num)
+	default:
+		return f.iface
+	}
+}
+
+// NewFields returns the Fields holding fields, to be passed to WithFields.
+func NewFields(fields ...Field) Fields {
+	f := make(Fields, len(fields))
+	for _, field := range fields {
+		f[field.Key] = field.Value()
+	}
+	return f
+}
+
+// hasField reports whether one of fields has key.
+func hasField(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Duration returns a field rendered as a duration string, e.g. "1.5s", by both backends.
+func Duration(key string, d time.Duration) Field {
+	return Field{Key: key, typ: durationField, num: int64(d)}
+}
+
+// Time returns a field rendered with the time format of the backend.
+func Time(key string, t time.Time) Field {
+	return Field{Key: key, iface: t}
+}
+
+// Code returns a field holding the canonical name of a GRPC code, e.g. "InvalidArgument".
+func Code(key string, c codes.Code) Field {
+	return Field{Key: key, typ: stringField, str: c.String()}
+}
+
+// String returns a string field.
+func String(key, value string) Field {
+	return Field{Key: key, typ: stringField, str: value}
+}
+
+// Int returns an int field.
+func Int(key string, value int) Field {
+	return Field{Key: key, typ: intField, num: int64(value)}
+}
+
+// Int64 returns an int64 field.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, typ: int64Field, num: value}
+}
+
+// Float64 returns a float64 field.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, typ: float64Field, num: int64(math.Float64bits(value))}
+}
+
+// Bool returns a bool field.
+func Bool(key string, value bool) Field {
+	var num int64
+	if value {
+		num = 1
+	}
+	return Field{Key: key, typ: boolField, num: num}
+}
+
+// Any returns a field of any value, rendered by the backend according to its type.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, iface: value}
+}
+
+// Err returns a field holding err under ErrorKey.
+func Err(err error) Field {
+	return Field{Key: ErrorKey(), iface: err}
+}
+
+// ByteSize is a number of bytes, logged as an integer or as "1.5 MiB" when Configuration.HumanizeBytes is set.
+type ByteSize int64
+
+// String formats b with an IEC suffix, e.g. "512 B", "1.5 MiB".
+func (b ByteSize) String() string {
+	const unit = 1024
+	if b < unit && b > -unit {
+		return strconv.FormatInt(int64(b), 10) + " B"
+	}
+	v, i := float64(b), -1
+	for (v >= unit || v <= -unit) && i < 5 {
+		v /= unit
+		i++
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0") + " " + "KMGTPE"[i:i+1] + "iB"
+}
+
+// Bytes returns a field holding a byte count, see ByteSize.
+func Bytes(key string, n int64) Field {
+	return Field{Key: key, typ: byteSizeField, num: n}
+}
+
+// byteSizeValue returns the value b is logged with.
+func byteSizeValue(b ByteSize, humanize bool) interface{} {
+	if humanize {
+		return b.String()
+	}
+	return int64(b)
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestTypedFieldsSerialize(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			child := l.WithAttrs(
+				String("string", "value"),
+				Int("int", -3),
+				Int64("int64", 1<<40),
+				Float64("float64", 1.5),
+				Bool("bool", true),
+				Bool("false", false),
+				Duration("duration", 1500*time.Millisecond),
+				Code("code", codes.NotFound),
+				Bytes("bytes", 2048),
+				Any("any", []int{1, 2}),
+				Err(errors.New("boom")),
+			)
+			entry := lastEntry(t, child, buf)
+			assertFields(t, entry, map[string]interface{}{
+				"string":   "value",
+				"int":      float64(-3),
+				"int64":    float64(1 << 40),
+				"float64":  1.5,
+				"bool":     true,
+				"false":    false,
+				"duration": "1.5s",
+				"code":     "NotFound",
+				"bytes":    float64(2048),
+				"error":    "boom",
+			})
+			if list, _ := entry["any"].([]interface{}); len(list) != 2 {
+				t.Errorf("field any = %v, want [1 2]", entry["any"])
+			}
+		})
+	}
+}
+
+func TestWithAttrsRepeatedKeyKeepsLast(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			entry := lastEntry(t, l.WithAttrs(String("k", "first"), Int("n", 1), String("k", "last")), buf)
+			assertFields(t, entry, map[string]interface{}{"k": "last", "n": float64(1)})
+		})
+	}
+}
+
+func TestSetErrorKey(t *testing.T) {
+	SetErrorKey("error.message")
+	t.Cleanup(func() { SetErrorKey("") })
+	boom := errors.New("boom")
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			assertFields(t, lastEntry(t, l.WithAttrs(Err(boom)), buf), map[string]interface{}{"error.message": "boom"}, "error")
+
+			resetGlobal(t)
+			if _, err := InitLogger(Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: buf}, backend); err != nil {
+				t.Fatalf("InitLogger: %v", err)
+			}
+			assertFields(t, lastEntry(t, WithError(boom), buf), map[string]interface{}{"error.message": "boom"}, "error")
+		})
+	}
+
+	SetErrorKey("")
+	if key := ErrorKey(); key != "error" {
+		t.Fatalf("ErrorKey() = %q after reset, want error", key)
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	tests := []struct {
+		field Field
+		want  interface{}
+	}{
+		{String("k", "v"), "v"},
+		{Int("k", 3), 3},
+		{Int64("k", 3), int64(3)},
+		{Float64("k", 2.5), 2.5},
+		{Bool("k", true), true},
+		{Duration("k", time.Second), time.Second},
+		{Bytes("k", 10), ByteSize(10)},
+		{Any("k", "any"), "any"},
+	}
+	for _, tt := range tests {
+		if got := tt.field.Value(); got != tt.want {
+			t.Errorf("Value() = %#v, want %#v", got, tt.want)
+		}
+	}
+	if got := NewFields(String("a", "x"), Int("b", 1)); got["a"] != "x" || got["b"] != 1 {
+		t.Errorf("NewFields = %v", got)
+	}
+}
+
+func benchmarkLogger(b *testing.B, backend LoggerBackend) Logger {
+	b.Helper()
+	l, err := NewLogger(Configuration{
+		EnableConsole:     true,
+		ConsoleJSONFormat: true,
+		ConsoleLevel:      infoLvl,
+		ConsoleWriter:     io.Discard,
+	}, backend)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return l
+}
+
+func BenchmarkWithAttrs(b *testing.B) {
+	for name, backend := range backends {
+		b.Run(name, func(b *testing.B) {
+			l := benchmarkLogger(b, backend)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.WithAttrs(String("method", "/svc/Method"), Int("attempt", i), Bool("retry", false))
+			}
+		})
+	}
+}
+
+func BenchmarkWithFieldsMap(b *testing.B) {
+	for name, backend := range backends {
+		b.Run(name, func(b *testing.B) {
+			l := benchmarkLogger(b, backend)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.WithFields(Fields{"method": "/svc/Method", "attempt": i, "retry": false})
+			}
+		})
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:                 "0 B",
+		512:               "512 B",
+		1023:              "1023 B",
+		1024:              "1 KiB",
+		1536:              "1.5 KiB",
+		1572864:           "1.5 MiB",
+		5 << 30:           "5 GiB",
+		-2048:             "-2 KiB",
+		1<<63 - 1:         "8 EiB",
+		3*(1<<40) + 1<<39: "3.5 TiB",
+	} {
+		if got := ByteSize(n).String(); got != want {
+			t.Errorf("ByteSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			for humanize, wantSize := range map[bool]interface{}{true: "1.5 MiB", false: float64(1572864)} {
+				l, buf := newBufferLogger(t, backend, Configuration{HumanizeBytes: humanize})
+				child := l.WithAttrs(Bytes("bytes_out", 1572864), Int("count", 1572864)).WithFields(Fields{"size": ByteSize(1572864), "n": int64(1572864)})
+				assertFields(t, lastEntry(t, child, buf), map[string]interface{}{
+					"bytes_out": wantSize,
+					"size":      wantSize,
+					"count":     float64(1572864),
+					"n":         float64(1572864),
+				})
+			}
+		})
+	}
+}
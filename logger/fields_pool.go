@@ -0,0 +1,28 @@
+package logger
+
+import "sync"
+
+var fieldsPool = sync.Pool{
+	New: func() interface{} {
+		return make(Fields, 8)
+	},
+}
+
+// AcquireFields returns an empty Fields map from a pool, to avoid allocating a map on hot paths.
+//
+// The caller owns the map until it passes it to ReleaseFields and must not use it afterwards.
+// It is safe to release the map right after WithFields returns, loggers never keep a reference to it.
+func AcquireFields() Fields {
+	return fieldsPool.Get().(Fields)
+}
+
+// ReleaseFields clears fields and returns it to the pool.
+func ReleaseFields(fields Fields) {
+	if fields == nil {
+		return
+	}
+	for k := range fields {
+		delete(fields, k)
+	}
+	fieldsPool.Put(fields)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import "testing"
+
+func TestReleasedFieldsDoNotLeak(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			fields := AcquireFields()
+			fields["user"] = "alice"
+			child := l.WithFields(fields)
+			ReleaseFields(fields)
+			if len(fields) != 0 {
+				t.Fatalf("released map still holds %v", fields)
+			}
+
+			// The pool may hand out the same map again, writing it must not change child.
+			reused := AcquireFields()
+			reused["user"] = "mallory"
+			reused["admin"] = true
+			assertFields(t, lastEntry(t, child, buf), map[string]interface{}{"user": "alice"}, "admin")
+			ReleaseFields(reused)
+		})
+	}
+}
+
+func BenchmarkFieldsPool(b *testing.B) {
+	for name, backend := range backends {
+		b.Run(name+"/pooled", func(b *testing.B) {
+			l := benchmarkLogger(b, backend)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fields := AcquireFields()
+				fields["method"] = "/svc/Method"
+				fields["retry"] = false
+				l.WithFields(fields)
+				ReleaseFields(fields)
+			}
+		})
+		b.Run(name+"/new map", func(b *testing.B) {
+			l := benchmarkLogger(b, backend)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fields := make(Fields, 8)
+				fields["method"] = "/svc/Method"
+				fields["retry"] = false
+				l.WithFields(fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// JSONCapture is a Logger encoding every level with the JSON encoder of the zap backend into memory,
+// e.g. to assert in tests that the emitted schema is stable.
+type JSONCapture struct {
+	Logger
+	buf *lockedBuffer
+}
+
+// NewJSONCapture returns a JSONCapture recording every level with the default field keys.
+func NewJSONCapture() *JSONCapture {
+	buf := &lockedBuffer{}
+	core := zapcore.NewCore(getEncoder(true, FieldKeys{}, false), buf, zapcore.DebugLevel)
+	sugared := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.WithFatalHook(zapExitHook{}), zap.WithClock(zapClock{})).Sugar()
+	return &JSONCapture{
+		Logger: &zapLogger{
+			sugaredLogger: sugared,
+			logger:        sugared.Desugar(),
+			base:          sugared.Desugar(),
+			core:          core,
+		},
+		buf: buf,
+	}
+}
+
+// Entries returns the entries emitted so far, each line decoded into a map. Lines which are not valid JSON are skipped.
+func (c *JSONCapture) Entries() []map[string]interface{} {
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(c.buf.bytes()))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// lockedBuffer is a zapcore.WriteSyncer appending to memory.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Sync() error {
+	return nil
+}
+
+func (b *lockedBuffer) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONCapture(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2024, 3, 1, 10, 20, 30, 500e6, time.UTC) })
+	t.Cleanup(func() { SetClock(nil) })
+	c := NewJSONCapture()
+	c.WithFields(Fields{"request_id": "req-1", "attempt": 2}).Debug("captured")
+	c.Errorf("failed %d times", 3)
+
+	entries := c.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	assertFields(t, entries[0], map[string]interface{}{
+		"level":      "debug",
+		"msg":        "captured",
+		"ts":         "2024-03-01T10:20:30.500Z",
+		"request_id": "req-1",
+		"attempt":    float64(2),
+	})
+	assertFields(t, entries[1], map[string]interface{}{"level": "error", "msg": "failed 3 times"}, "request_id")
+	for _, entry := range entries {
+		if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "logger/json_capture_test.go:") {
+			t.Errorf("caller = %q, want this file", caller)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package logger
+
+import "fmt"
+
+// LazyValue is a field value computed only when the entry is emitted, see Lazy.
+type LazyValue struct {
+	fn func() interface{}
+}
+
+// Lazy returns a field value which calls fn only if the entry it belongs to is actually emitted,
+// e.g. WithFields(Fields{"payload": Lazy(func() interface{} { return dump(req) })}).
+// fn may be called once per emitted entry.
+func Lazy(fn func() interface{}) LazyValue {
+	return LazyValue{fn: fn}
+}
+
+// Value calls the function of v.
+func (v LazyValue) Value() interface{} {
+	return v.fn()
+}
+
+// String implements fmt.Stringer so that loggers formatting values with fmt evaluate v.
+func (v LazyValue) String() string {
+	return fmt.Sprint(v.fn())
+}
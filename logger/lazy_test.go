@@ -0,0 +1,28 @@
+package logger
+
+import "testing"
+
+func TestLazyEvaluatedOnlyWhenEmitted(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{ConsoleLevel: infoLvl})
+			calls := 0
+			child := l.WithFields(Fields{"payload": Lazy(func() interface{} {
+				calls++
+				return "expensive"
+			})})
+
+			child.Debug("below the level")
+			if calls != 0 {
+				t.Fatalf("lazy value evaluated %d times for a suppressed entry", calls)
+			}
+			if buf.Len() != 0 {
+				t.Fatalf("suppressed entry written: %q", buf.String())
+			}
+			assertFields(t, lastEntry(t, child, buf), map[string]interface{}{"payload": "expensive"})
+			if calls != 1 {
+				t.Fatalf("lazy value evaluated %d times, want 1", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import "fmt"
+
+// Level is a log level, see the constants. The zero value is unset and falls back to the backend default.
+type Level string
+
+// Levels, from the most verbose to the most severe.
+const (
+	DebugLevel Level = debugLvl
+	InfoLevel  Level = infoLvl
+	WarnLevel  Level = warnLvl
+	ErrorLevel Level = errorLvl
+	FatalLevel Level = fatalLvl
+	PanicLevel Level = panicLvl
+)
+
+// ParseLevel returns the Level named s, e.g. "info", or an error naming the valid levels.
+func ParseLevel(s string) (Level, error) {
+	l := Level(s)
+	if s == "" || !l.IsValid() {
+		return "", fmt.Errorf("invalid level %q, valid levels are debug, info, warn, error, fatal and panic", s)
+	}
+	return l, nil
+}
+
+func (l Level) String() string {
+	return string(l)
+}
+
+// IsValid reports whether l is one of the level constants or unset.
+func (l Level) IsValid() bool {
+	return isValidLevel(string(l))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, it fails on unknown levels.
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
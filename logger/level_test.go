@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, want := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel} {
+		got, err := ParseLevel(want.String())
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = (%q, %v), want %q", want, got, err, want)
+		}
+	}
+	for _, invalid := range []string{"", "INFO", "trace", "warning"} {
+		if _, err := ParseLevel(invalid); err == nil {
+			t.Errorf("ParseLevel(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestLevelTextRoundTrip(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel} {
+		b, err := json.Marshal(config{Level: level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got config
+		if err := json.Unmarshal(b, &got); err != nil || got.Level != level {
+			t.Errorf("round trip of %q = (%q, %v)", level, got.Level, err)
+		}
+	}
+	var got config
+	if err := json.Unmarshal([]byte(`{"level":"verbose"}`), &got); err == nil {
+		t.Error("unmarshal of an unknown level succeeded")
+	}
+}
+
+func TestConsoleLevelTyped(t *testing.T) {
+	if err := (Configuration{ConsoleLevelTyped: "verbose"}).Validate(); err == nil {
+		t.Error("invalid typed level accepted")
+	}
+	if err := (Configuration{ConsoleLevel: "info", ConsoleLevelTyped: ErrorLevel}).Validate(); err == nil {
+		t.Error("conflicting levels accepted")
+	}
+	cfg := Configuration{ConsoleLevelTyped: WarnLevel}.withTypedLevels()
+	if cfg.ConsoleLevel != warnLvl {
+		t.Errorf("ConsoleLevel = %q, want %q", cfg.ConsoleLevel, warnLvl)
+	}
+	cfg = Configuration{ConsoleLevel: debugLvl}.withTypedLevels()
+	if cfg.ConsoleLevelTyped != DebugLevel {
+		t.Errorf("ConsoleLevelTyped = %q, want %q", cfg.ConsoleLevelTyped, DebugLevel)
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{ConsoleLevel: "", ConsoleLevelTyped: ErrorLevel})
+			l.Warn("dropped")
+			l.Error("kept")
+			es := entries(t, buf)
+			if len(es) != 1 || es[0]["msg"] != "kept" {
+				t.Fatalf("entries = %v, want only the error one", es)
+			}
+		})
+	}
+}
+
+func TestPanicLevelFiltersLowerLevels(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{ConsoleLevel: panicLvl})
+			l.Info("info")
+			l.Error("error")
+			if es := entries(t, buf); len(es) != 0 {
+				t.Fatalf("entries = %v, want none below panic", es)
+			}
+		})
+	}
+}
@@ -1,14 +1,41 @@
 package logger
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
 	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/linhbkhn95/golang-british/appmode"
 )
 
 // A global variable so that log functions can be directly accessed
 var log = DefaultLogger()
 
+// warnOutput receives the warnings which cannot go through a logger, e.g. about dropped async entries or invalid
+// environment variables. They are not written to the log output whose format, e.g. JSON, they would break.
+var warnOutput io.Writer = os.Stderr
+
+// defaultLog is used by the package functions in place of log if it is nil.
+var defaultLog = sync.OnceValue(DefaultLogger)
+
+// global returns the global logger, DefaultLogger when it is not set, so that the package functions never panic.
+func global() Logger {
+	if log == nil {
+		return defaultLog()
+	}
+	return log
+}
+
 // Fields Type to pass when we want to call WithFields for structured logging
 type Fields map[string]interface{}
 
@@ -27,6 +54,8 @@ const (
 	errorLvl = "error"
 	// Fatal is for logging fatal messages. The sytem shutsdown after logging the message.
 	fatalLvl = "fatal"
+	// Panic is for logging panic messages. The logger panics after logging the message.
+	panicLvl = "panic"
 )
 
 const (
@@ -39,7 +68,23 @@ const (
 var (
 	errInvalidLoggerInstance = errors.New("invalid logger instance")
 
+	// ErrFileLocationRequired is returned by NewLogger, for both backends, when EnableFile is set without FileLocation nor FileWriter.
+	// It is returned even when StrictFile is false, an empty location is a configuration mistake rather than
+	// an unwritable file.
+	ErrFileLocationRequired = errors.New("file location is required when file logging is enabled")
+
+	// ErrAlreadyInitialized is returned by InitLogger when called again with another config than the first call,
+	// along with the logger built by the first call.
+	ErrAlreadyInitialized = errors.New("logger already initialized with another config")
+
 	once sync.Once
+	// initConfig and initBackend are the parameters of the first InitLogger call, initErr its error.
+	initConfig  Configuration
+	initBackend LoggerBackend
+	initErr     error
+
+	// exitFunc is called by the fatal paths after the entry has been written.
+	exitFunc = os.Exit
 )
 
 // Logger is our contract for the logger
@@ -62,23 +107,100 @@ type Logger interface {
 	Panic(msg string)
 	Panicf(format string, args ...interface{})
 
+	// WithFields returns a new logger carrying keyValues on top of the fields of the current one.
+	// Later keys override earlier keys with the same name, and the returned logger is independent:
+	// neither the receiver nor sibling loggers built from it are affected.
+	// keyValues is copied, the caller may reuse it (or release it with ReleaseFields) once WithFields returns.
 	WithFields(keyValues Fields) Logger
 
+	// WithAttrs is like WithFields for fields built with the typed constructors, e.g. String or Int.
+	WithAttrs(fields ...Field) Logger
+
+	// WithGroup returns a new logger whose subsequent fields are nested under name, e.g. {"db": {"rows": 5}}
+	// in JSON. The fields added before stay at their level. An empty name returns the receiver.
+	WithGroup(name string) Logger
+
 	GetDelegate() interface{}
 
 	Sync() error
+
+	// SyncContext is like Sync but returns ctx.Err() if the flush does not complete before ctx is done.
+	SyncContext(ctx context.Context) error
+
+	// Close syncs the logger and closes the files it opened. Loggers built with WithFields share the files
+	// of their parent, closing any of them closes the files for all.
+	Close() error
+
+	// Rotate syncs the logger and starts new log files, the current ones being archived. No-op without file.
+	Rotate() error
 }
 
 // Configuration stores the config for the logger
-// For some loggers there can only be one level across writers, for such the level of Console is picked by default
+// The zap backend honors ConsoleLevel and FileLevel independently, each writer filters on its own level.
+// Console and file formats are always independent, e.g. text on the console and JSON in the file from the same logger.
+// For some loggers (logrus) there can only be one level across writers, for such the level of Console is picked by default
 type Configuration struct {
 	EnableConsole     bool   `name:"log-enable-console" help:"Enable log console" env:"LOG_ENABLE_CONSOLE" default:"true" yaml:"enable_console" mapstructure:"enable_console"`
 	ConsoleJSONFormat bool   `name:"log-console-json-format" help:"Console to json format" env:"LOG_CONSOLE_JSON_FORMAT" default:"false" yaml:"console_log_format" mapstructure:"console_log_format"`
 	ConsoleLevel      string `name:"log-console-level" help:"Console log level" env:"LOG_CONSOLE_LEVEL" default:"info" enum:"debug, info, warn, error, fatal, panic" yaml:"console_level" mapstructure:"console_level"`
-	EnableFile        bool
-	FileJSONFormat    bool
-	FileLevel         string
-	FileLocation      string
+	// ConsoleLevelTyped is ConsoleLevel as a Level, it takes precedence when set. NewLogger fails when both are set
+	// to different levels.
+	ConsoleLevelTyped Level
+	EnableFile        bool   `name:"log-enable-file" help:"Enable log file" env:"LOG_ENABLE_FILE" default:"false" yaml:"enable_file" mapstructure:"enable_file"`
+	FileJSONFormat    bool   `name:"log-file-json-format" help:"File to json format" env:"LOG_FILE_JSON_FORMAT" default:"false" yaml:"file_log_format" mapstructure:"file_log_format"`
+	FileLevel         string `name:"log-file-level" help:"File log level" env:"LOG_FILE_LEVEL" default:"info" enum:"debug, info, warn, error, fatal, panic" yaml:"file_level" mapstructure:"file_level"`
+	FileLocation      string `name:"log-file-location" help:"Log file path" env:"LOG_FILE_LOCATION" yaml:"file_location" mapstructure:"file_location"`
+	// ConsoleWriter and FileWriter replace stdout and FileLocation as the console and file outputs when set,
+	// e.g. to write to a network sink or a buffer. They are neither rotated nor closed by the logger.
+	ConsoleWriter io.Writer
+	FileWriter    io.Writer
+	// FileBufferSize buffers file writes in memory up to this size in bytes, the buffer is flushed on Sync,
+	// Rotate and Close. Writes are unbuffered when 0.
+	FileBufferSize int
+	// FileCompress gzips the rotated files, enabled when nil. FileMaxAgeDays is the number of days rotated files are kept,
+	// 28 when 0 and forever when negative. Old files are removed on rotation, and also every FileRetentionInterval
+	// when it is set.
+	FileCompress          *bool
+	FileMaxAgeDays        int
+	FileRetentionInterval time.Duration
+	// StrictFile makes NewLogger fail when FileLocation cannot be written. By default a warning is logged
+	// and entries go to the console only.
+	StrictFile bool
+	// Async writes entries from a background goroutine so that callers never wait on the writer.
+	// Entries are dropped with a periodic warning on stderr when the buffer of AsyncBufferSize entries is full,
+	// Sync drains the buffer before returning and Close stops the goroutine.
+	Async           bool
+	AsyncBufferSize int
+	// EnableSyslog forwards entries to syslog at ConsoleLevel. An empty SyslogNetwork connects to the local daemon.
+	// When syslog is unreachable, a warning is logged and entries go to the console instead.
+	EnableSyslog  bool
+	SyslogNetwork string
+	SyslogAddr    string
+	SyslogTag     string
+	// HumanizeBytes logs the ByteSize fields, built with Bytes, as "1.5 MiB" rather than as an integer.
+	HumanizeBytes bool
+	// IncludeHostname and IncludePID add the host and pid fields to every entry, e.g. to tell instances apart.
+	IncludeHostname bool
+	IncludePID      bool
+	// StacktraceLevel adds the stack trace of the caller to entries at or above this level. Disabled when empty.
+	StacktraceLevel string
+	// ColorConsole colors the levels of the non JSON console output. When nil, colors are enabled if the console
+	// output is a terminal.
+	ColorConsole *bool
+	FieldKeys    FieldKeys
+	// FatalPanics makes Fatal panic after logging so that the stack is visible, e.g. in development.
+	// By default Fatal exits the process with status 1.
+	FatalPanics bool `name:"log-fatal-panics" help:"Panic instead of exiting on fatal" env:"LOG_FATAL_PANICS" default:"false" yaml:"fatal_panics" mapstructure:"fatal_panics"`
+}
+
+// FieldKeys overrides the keys of the built-in entry fields. Empty keys keep the backend defaults.
+// NameKey is only supported by zap.
+type FieldKeys struct {
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	NameKey    string
+	CallerKey  string
 }
 
 // DefaultLogger creates default logger, which uses zap sugarlogger and outputs to console
@@ -94,94 +216,339 @@ func DefaultLogger() Logger {
 	return logger
 }
 
+// ConfigForMode returns the baseline configuration of mode: console only, text at debug level with Fatal panicking
+// in Development, JSON at info level in Production.
+func ConfigForMode(mode appmode.AppMode) Configuration {
+	if mode == appmode.Production {
+		return Configuration{
+			EnableConsole:     true,
+			ConsoleJSONFormat: true,
+			ConsoleLevel:      infoLvl,
+		}
+	}
+	return Configuration{
+		EnableConsole:     true,
+		ConsoleJSONFormat: false,
+		ConsoleLevel:      debugLvl,
+		FatalPanics:       true,
+	}
+}
+
 // InitLogger returns an instance of logger
+// Only the first call builds the global logger. Later calls return it, with ErrAlreadyInitialized
+// when their config or backend differ from the ones of the first call.
+// When the first call fails, its error is returned again and the global logger stays DefaultLogger.
 func InitLogger(config Configuration, backend LoggerBackend) (Logger, error) {
-	var err error
 	once.Do(func() {
-		switch backend {
-		case LoggerBackendZap:
-			log, err = NewLogger(config, backend)
-
-		case LoggerBackendLogrus:
-			log, err = NewLogger(config, backend)
-
-		default:
-			err = errInvalidLoggerInstance
+		initConfig, initBackend = config, backend
+		var l Logger
+		// The global logger is only replaced on success, so that it is never nil.
+		l, initErr = NewLogger(config, backend)
+		if initErr == nil {
+			log = l
 		}
 	})
-	return log, err
+	if backend != initBackend || !reflect.DeepEqual(config, initConfig) {
+		return global(), ErrAlreadyInitialized
+	}
+	if initErr != nil {
+		return nil, initErr
+	}
+	return log, nil
+}
+
+// SetExitFunc replaces the function called by Fatal and Fatalf unless FatalPanics is set, os.Exit by default.
+// It is meant for tests which need to assert the fatal path without killing the test binary.
+func SetExitFunc(fn func(int)) {
+	exitFunc = fn
+}
+
+// Validate checks that the levels belong to the allowed set and that a file location is set when file logging is enabled.
+// Empty levels are allowed and fall back to the backend default.
+func (c Configuration) Validate() error {
+	var err error
+	if !isValidLevel(c.ConsoleLevel) {
+		err = multierr.Append(err, fmt.Errorf("invalid console level %q", c.ConsoleLevel))
+	}
+	if !c.ConsoleLevelTyped.IsValid() {
+		err = multierr.Append(err, fmt.Errorf("invalid typed console level %q", c.ConsoleLevelTyped))
+	}
+	if c.ConsoleLevel != "" && c.ConsoleLevelTyped != "" && c.ConsoleLevel != string(c.ConsoleLevelTyped) {
+		err = multierr.Append(err, fmt.Errorf("console level %q and typed console level %q differ", c.ConsoleLevel, c.ConsoleLevelTyped))
+	}
+	if !isValidLevel(c.FileLevel) {
+		err = multierr.Append(err, fmt.Errorf("invalid file level %q", c.FileLevel))
+	}
+	if !isValidLevel(c.StacktraceLevel) {
+		err = multierr.Append(err, fmt.Errorf("invalid stacktrace level %q", c.StacktraceLevel))
+	}
+	if c.EnableFile && c.FileLocation == "" && c.FileWriter == nil {
+		err = multierr.Append(err, ErrFileLocationRequired)
+	}
+	return err
+}
+
+// withTypedLevels returns c with ConsoleLevel and ConsoleLevelTyped derived from each other.
+func (c Configuration) withTypedLevels() Configuration {
+	if c.ConsoleLevelTyped != "" {
+		c.ConsoleLevel = string(c.ConsoleLevelTyped)
+	} else {
+		c.ConsoleLevelTyped = Level(c.ConsoleLevel)
+	}
+	return c
+}
+
+// checkFileWritable returns an error when file logging is enabled and FileLocation cannot be opened for writing.
+func (c Configuration) checkFileWritable() error {
+	if !c.EnableFile || c.FileWriter != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.FileLocation), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.FileLocation, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// consoleWriter returns the console output, ConsoleWriter when set, stdout otherwise.
+func (c Configuration) consoleWriter() io.Writer {
+	if c.ConsoleWriter != nil {
+		return c.ConsoleWriter
+	}
+	return os.Stdout
+}
+
+// consoleColored reports whether console levels must be colored.
+func (c Configuration) consoleColored() bool {
+	if c.ConsoleJSONFormat {
+		return false
+	}
+	if c.ColorConsole != nil {
+		return *c.ColorConsole
+	}
+	f, ok := c.consoleWriter().(*os.File)
+	return ok && isTerminal(f)
+}
+
+// isTerminal reports whether f is a terminal rather than a regular file or a pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func isValidLevel(level string) bool {
+	switch level {
+	case "", debugLvl, infoLvl, warnLvl, errorLvl, fatalLvl, panicLvl:
+		return true
+	default:
+		return false
+	}
+}
+
+// MustInitLogger is like InitLogger but panics on error, ErrAlreadyInitialized included, for setup code in main.
+func MustInitLogger(config Configuration, backend LoggerBackend) Logger {
+	l, err := InitLogger(config, backend)
+	if err != nil {
+		panic(fmt.Sprintf("init logger: %v", err))
+	}
+	return l
 }
 
 func NewLogger(config Configuration, backend LoggerBackend) (Logger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	config = config.withTypedLevels()
+	var (
+		l   Logger
+		err error
+	)
 	switch backend {
 	case LoggerBackendZap:
-		return newZapLogger(config)
+		l, err = newZapLogger(config)
 
 	case LoggerBackendLogrus:
-		return newLogrusLogger(config)
+		l, err = newLogrusLogger(config)
 
 	default:
 		return nil, errInvalidLoggerInstance
 	}
+	if err != nil {
+		return nil, err
+	}
+	fields, err := config.processFields()
+	if err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	if len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+	return l, nil
 }
 
+// processFields returns the host and pid fields enabled by IncludeHostname and IncludePID, resolved once.
+func (c Configuration) processFields() (Fields, error) {
+	fields := Fields{}
+	if c.IncludeHostname {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("resolve hostname: %w", err)
+		}
+		fields["host"] = host
+	}
+	if c.IncludePID {
+		fields["pid"] = os.Getpid()
+	}
+	return fields, nil
+}
+
+// Debug, Info, Warn, Error, Fatal and Panic log msg as is, without formatting it.
 func Debug(msg string) {
-	log.Debugf(msg)
+	global().Debug(msg)
 }
 
 func Debugf(format string, args ...interface{}) {
-	log.Debugf(format, args...)
+	global().Debugf(format, args...)
 }
 
 func Info(msg string) {
-	log.Infof(msg)
+	global().Info(msg)
 }
 
 func Infof(format string, args ...interface{}) {
-	log.Infof(format, args...)
+	global().Infof(format, args...)
 }
 
 func Warn(msg string) {
-	log.Warnf(msg)
+	global().Warn(msg)
 }
 
 func Warnf(format string, args ...interface{}) {
-	log.Warnf(format, args...)
+	global().Warnf(format, args...)
 }
 
 func Error(msg string) {
-	fmt.Println(msg)
-	log.Errorf(msg)
+	global().Error(msg)
 }
 
 func Errorf(format string, args ...interface{}) {
-	log.Errorf(format, args...)
+	global().Errorf(format, args...)
 }
 
 func Fatal(msg string) {
-	log.Fatalf(msg)
+	global().Fatal(msg)
 }
 
 func Fatalf(format string, args ...interface{}) {
-	log.Fatalf(format, args...)
+	global().Fatalf(format, args...)
 }
 
 func Panic(msg string) {
-	log.Panicf(msg)
+	global().Panic(msg)
 }
 
 func Panicf(format string, args ...interface{}) {
-	log.Panicf(format, args...)
+	global().Panicf(format, args...)
 }
 
 func Sync() error {
-	return log.Sync()
+	return global().Sync()
+}
+
+func Close() error {
+	return global().Close()
+}
+
+func Rotate() error {
+	return global().Rotate()
+}
+
+// RotateOnSIGHUP rotates the files of the global logger every time the process receives SIGHUP,
+// until the returned stop function is called.
+func RotateOnSIGHUP() (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				if err := Rotate(); err != nil {
+					Errorf("rotate log files: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
+
+func SyncContext(ctx context.Context) error {
+	return global().SyncContext(ctx)
+}
+
+// closeAll syncs with sync then closes every closer, errors are joined together.
+func closeAll(sync func() error, closers []io.Closer) error {
+	err := sync()
+	for _, c := range closers {
+		err = multierr.Append(err, c.Close())
+	}
+	return err
+}
+
+// rotateAll syncs with sync then rotates every closer supporting it, i.e. lumberjack files.
+func rotateAll(sync func() error, closers []io.Closer) error {
+	err := sync()
+	for _, c := range closers {
+		if r, ok := c.(interface{ Rotate() error }); ok {
+			err = multierr.Append(err, r.Rotate())
+		}
+	}
+	return err
+}
+
+// syncContext runs sync in a goroutine and gives up when ctx is done. sync keeps running in the background.
+func syncContext(ctx context.Context, sync func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- sync()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func WithFields(keyValues Fields) Logger {
-	return log.WithFields(keyValues)
+	return global().WithFields(keyValues)
+}
+
+func WithAttrs(fields ...Field) Logger {
+	return global().WithAttrs(fields...)
+}
+
+func WithGroup(name string) Logger {
+	return global().WithGroup(name)
+}
+
+// WithError returns a logger carrying err under ErrorKey.
+func WithError(err error) Logger {
+	return global().WithFields(Fields{ErrorKey(): err})
 }
 
 func GetDelegate() interface{} {
-	return log.GetDelegate()
+	return global().GetDelegate()
 }
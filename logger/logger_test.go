@@ -0,0 +1,583 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/linhbkhn95/golang-british/appmode"
+)
+
+func TestConsoleWriterAndFileWriter(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			var console, file bytes.Buffer
+			l, err := NewLogger(Configuration{
+				EnableConsole:  true,
+				ConsoleLevel:   infoLvl,
+				ConsoleWriter:  &console,
+				EnableFile:     true,
+				FileLevel:      infoLvl,
+				FileJSONFormat: true,
+				FileWriter:     &file,
+			}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.Info("hello")
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if !strings.Contains(console.String(), "hello") {
+				t.Errorf("console output %q does not contain the entry", console.String())
+			}
+			if !strings.Contains(file.String(), `"msg":"hello"`) {
+				t.Errorf("file output %q does not contain the JSON entry", file.String())
+			}
+		})
+	}
+}
+
+func TestFileWriterWithoutLocation(t *testing.T) {
+	if err := (Configuration{EnableFile: true, FileWriter: &bytes.Buffer{}}).Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := (Configuration{EnableFile: true}).Validate(); err == nil {
+		t.Fatal("file logging without location nor writer accepted")
+	}
+}
+
+func TestConsoleWriterIsNotColored(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			var console bytes.Buffer
+			l, err := NewLogger(Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: &console}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.Error("hello")
+			if strings.Contains(console.String(), "\x1b[") {
+				t.Fatalf("console output %q contains ANSI codes", console.String())
+			}
+		})
+	}
+}
+
+func TestFieldKeys(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{FieldKeys: FieldKeys{
+				TimeKey:    "@timestamp",
+				LevelKey:   "severity",
+				MessageKey: "message",
+			}})
+			entry := lastEntry(t, l, buf)
+			assertFields(t, entry, map[string]interface{}{"severity": "info", "message": "msg"}, "ts", "time", "level", "msg")
+			if _, ok := entry["@timestamp"]; !ok {
+				t.Errorf("@timestamp missing in %v", entry)
+			}
+		})
+	}
+}
+
+func TestFatalPanics(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{FatalPanics: true})
+			exited := false
+			SetExitFunc(func(int) { exited = true })
+			t.Cleanup(func() { SetExitFunc(os.Exit) })
+
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("Fatal did not panic")
+					}
+				}()
+				l.Fatal("fatal line")
+			}()
+			if exited {
+				t.Error("exit func called")
+			}
+			if !strings.Contains(buf.String(), "fatal line") {
+				t.Errorf("output %q does not contain the fatal entry", buf.String())
+			}
+		})
+	}
+}
+
+func TestConfigForMode(t *testing.T) {
+	dev := ConfigForMode(appmode.Development)
+	if !dev.EnableConsole || dev.ConsoleJSONFormat || dev.ConsoleLevel != debugLvl {
+		t.Errorf("development config = %+v, want a text console at debug level", dev)
+	}
+	prod := ConfigForMode(appmode.Production)
+	if !prod.EnableConsole || !prod.ConsoleJSONFormat || prod.ConsoleLevel != infoLvl {
+		t.Errorf("production config = %+v, want a JSON console at info level", prod)
+	}
+	for _, mode := range []appmode.AppMode{appmode.Development, appmode.Production} {
+		if err := ConfigForMode(mode).Validate(); err != nil {
+			t.Errorf("config of %v is invalid: %v", mode, err)
+		}
+	}
+}
+
+func TestConfigForModeFatal(t *testing.T) {
+	if !ConfigForMode(appmode.Development).FatalPanics {
+		t.Error("Fatal does not panic in development")
+	}
+	if ConfigForMode(appmode.Production).FatalPanics {
+		t.Error("Fatal panics in production")
+	}
+}
+
+func TestFatalCallsExitFunc(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			var codes []int
+			var written bool
+			SetExitFunc(func(code int) {
+				codes = append(codes, code)
+				written = strings.Contains(buf.String(), "fatal line")
+			})
+			t.Cleanup(func() { SetExitFunc(os.Exit) })
+
+			l.Fatal("fatal line")
+			if len(codes) != 1 || codes[0] != 1 {
+				t.Fatalf("exit codes = %v, want [1]", codes)
+			}
+			if !written {
+				t.Fatal("entry not written before exit")
+			}
+		})
+	}
+}
+
+func TestConfigurationValidate(t *testing.T) {
+	valid := Configuration{EnableConsole: true, ConsoleLevel: infoLvl, EnableFile: true, FileLevel: debugLvl, FileLocation: "app.log"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate of a valid configuration: %v", err)
+	}
+	for name, change := range map[string]func(c *Configuration){
+		"console level":    func(c *Configuration) { c.ConsoleLevel = "warning" },
+		"file level":       func(c *Configuration) { c.FileLevel = "verbose" },
+		"stacktrace level": func(c *Configuration) { c.StacktraceLevel = "all" },
+		"typed level":      func(c *Configuration) { c.ConsoleLevelTyped = "trace" },
+		"levels differ":    func(c *Configuration) { c.ConsoleLevelTyped = ErrorLevel },
+		"file location":    func(c *Configuration) { c.FileLocation = "" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := valid
+			change(&c)
+			if err := c.Validate(); err == nil {
+				t.Fatal("Validate accepted the configuration")
+			}
+			if _, err := NewLogger(c, LoggerBackendZap); err == nil {
+				t.Fatal("NewLogger accepted the configuration")
+			}
+		})
+	}
+
+	invalid := Configuration{ConsoleLevel: "warning", FileLevel: "verbose", EnableFile: true}
+	if n := len(multierr.Errors(invalid.Validate())); n != 3 {
+		t.Fatalf("Validate returned %d errors, want 3", n)
+	}
+	if !errors.Is(invalid.Validate(), ErrFileLocationRequired) {
+		t.Fatal("Validate error is not ErrFileLocationRequired")
+	}
+}
+
+func TestColorConsole(t *testing.T) {
+	for name, backend := range backends {
+		for _, color := range []bool{true, false} {
+			color := color
+			t.Run(fmt.Sprintf("%s color %t", name, color), func(t *testing.T) {
+				var console bytes.Buffer
+				l, err := NewLogger(Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: &console, ColorConsole: &color}, backend)
+				if err != nil {
+					t.Fatalf("NewLogger: %v", err)
+				}
+				l.Error("hello")
+				if got := strings.Contains(console.String(), "\x1b["); got != color {
+					t.Fatalf("output %q contains ANSI codes: %t, want %t", console.String(), got, color)
+				}
+			})
+		}
+	}
+}
+
+func TestColorConsoleIgnoredForJSON(t *testing.T) {
+	color := true
+	if (Configuration{ConsoleJSONFormat: true, ColorConsole: &color}).consoleColored() {
+		t.Fatal("JSON console output is colored")
+	}
+}
+
+func TestDualWriteFormats(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			var console bytes.Buffer
+			path := filepath.Join(t.TempDir(), "app.log")
+			l, err := NewLogger(Configuration{
+				EnableConsole:  true,
+				ConsoleLevel:   infoLvl,
+				ConsoleWriter:  &console,
+				EnableFile:     true,
+				FileLevel:      infoLvl,
+				FileJSONFormat: true,
+				FileLocation:   path,
+			}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.WithFields(Fields{"user": "alice"}).Info("dual write")
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			line := strings.TrimSpace(console.String())
+			if !strings.Contains(line, "dual write") || !strings.Contains(line, "alice") {
+				t.Errorf("console output %q does not contain the entry", line)
+			}
+			if json.Valid([]byte(line)) {
+				t.Errorf("console output %q is JSON, want text", line)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read log file: %v", err)
+			}
+			var entry map[string]interface{}
+			if err := json.Unmarshal(bytes.TrimSpace(content), &entry); err != nil {
+				t.Fatalf("file content %q is not one JSON line: %v", content, err)
+			}
+			assertFields(t, entry, map[string]interface{}{"msg": "dual write", "user": "alice"})
+		})
+	}
+}
+
+func TestJSONConsoleAndTextFile(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			var console, file bytes.Buffer
+			l, err := NewLogger(Configuration{
+				EnableConsole:     true,
+				ConsoleJSONFormat: true,
+				ConsoleLevel:      infoLvl,
+				ConsoleWriter:     &console,
+				EnableFile:        true,
+				FileLevel:         infoLvl,
+				FileWriter:        &file,
+			}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.Info("formats")
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if !json.Valid(bytes.TrimSpace(console.Bytes())) {
+				t.Errorf("console output %q is not JSON", console.String())
+			}
+			if line := bytes.TrimSpace(file.Bytes()); json.Valid(line) || !bytes.Contains(line, []byte("formats")) {
+				t.Errorf("file output %q is not the text entry", line)
+			}
+		})
+	}
+}
+
+// resetGlobal forgets the global logger and the first InitLogger call, and restores them when t ends.
+func resetGlobal(t testing.TB) {
+	t.Helper()
+	savedLog, savedConfig, savedBackend, savedErr := log, initConfig, initBackend, initErr
+	once, log, initConfig, initBackend, initErr = sync.Once{}, DefaultLogger(), Configuration{}, 0, nil
+	t.Cleanup(func() {
+		// once is left done, a restored global must not be built again.
+		once.Do(func() {})
+		log, initConfig, initBackend, initErr = savedLog, savedConfig, savedBackend, savedErr
+	})
+}
+
+func TestInitLoggerTwice(t *testing.T) {
+	resetGlobal(t)
+	buf := &bytes.Buffer{}
+	config := Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: buf}
+	first, err := InitLogger(config, LoggerBackendZap)
+	if err != nil {
+		t.Fatalf("InitLogger: %v", err)
+	}
+
+	if l, err := InitLogger(config, LoggerBackendZap); err != nil || l != first {
+		t.Fatalf("second InitLogger with the same config = (%v, %v), want the first logger", l, err)
+	}
+	other := config
+	other.ConsoleLevel = debugLvl
+	for name, tc := range map[string]struct {
+		config  Configuration
+		backend LoggerBackend
+	}{
+		"other config":  {config: other, backend: LoggerBackendZap},
+		"other backend": {config: config, backend: LoggerBackendLogrus},
+	} {
+		t.Run(name, func(t *testing.T) {
+			l, err := InitLogger(tc.config, tc.backend)
+			if !errors.Is(err, ErrAlreadyInitialized) || l != first {
+				t.Fatalf("InitLogger = (%v, %v), want the first logger and ErrAlreadyInitialized", l, err)
+			}
+		})
+	}
+	// The first config still applies.
+	Debug("debug line")
+	if buf.Len() != 0 {
+		t.Fatalf("debug entry written with the first config at info level: %q", buf.String())
+	}
+}
+
+func TestPackageFunctionsAfterFailedInit(t *testing.T) {
+	resetGlobal(t)
+	_, initErr := InitLogger(Configuration{EnableConsole: true, ConsoleLevel: "verbose"}, LoggerBackendZap)
+	if initErr == nil {
+		t.Fatal("InitLogger with an invalid level succeeded")
+	}
+	if log == nil {
+		t.Fatal("global logger is nil after a failed InitLogger")
+	}
+	if l, err := InitLogger(Configuration{EnableConsole: true, ConsoleLevel: "verbose"}, LoggerBackendZap); err != initErr || l != nil {
+		t.Fatalf("second InitLogger = (%v, %v), want the first error %v", l, err, initErr)
+	}
+
+	Debug("debug")
+	Infof("info %d", 1)
+	WithFields(Fields{"k": "v"}).Warn("warn")
+	WithError(errors.New("boom")).Error("error")
+	WithGroup("g").WithAttrs(String("k", "v")).Info("grouped")
+	FromContext(context.Background()).Info("from context")
+	_ = Sync()
+}
+
+func TestPackageFunctionsBeforeInit(t *testing.T) {
+	for name, global := range map[string]Logger{"default": DefaultLogger(), "nil": nil} {
+		t.Run(name, func(t *testing.T) {
+			resetGlobal(t)
+			log = global
+			Debug("debug")
+			Debugf("debug %d", 1)
+			Info("info")
+			Infof("info %d", 1)
+			Warn("warn")
+			Warnf("warn %d", 1)
+			Error("error")
+			Errorf("error %d", 1)
+			WithFields(Fields{"k": "v"}).Info("fields")
+			WithAttrs(String("k", "v")).Info("attrs")
+			WithGroup("g").Info("grouped")
+			WithError(errors.New("boom")).Error("with error")
+			FromContext(context.Background()).Info("from context")
+			if GetDelegate() == nil {
+				t.Error("GetDelegate returned nil")
+			}
+			// Syncing stdout may fail, only the absence of panic matters.
+			_ = Sync()
+			_ = SyncContext(context.Background())
+			_ = Rotate()
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("Panic did not panic")
+					}
+				}()
+				Panic("panic")
+			}()
+		})
+	}
+}
+
+func TestMustInitLogger(t *testing.T) {
+	resetGlobal(t)
+	var buf bytes.Buffer
+	l := MustInitLogger(Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: &buf}, LoggerBackendZap)
+	l.Info("must line")
+	if !strings.Contains(buf.String(), "must line") {
+		t.Fatalf("output %q does not contain the entry", buf.String())
+	}
+
+	resetGlobal(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustInitLogger did not panic for an invalid backend")
+		}
+	}()
+	MustInitLogger(Configuration{EnableConsole: true}, LoggerBackend(42))
+}
+
+// unwritablePath returns a log file location whose parent is a regular file, unwritable even by root.
+func unwritablePath(t *testing.T) string {
+	t.Helper()
+	parent := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(parent, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(parent, "app.log")
+}
+
+func TestUnwritableFileFallsBackToConsole(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{EnableFile: true, FileLocation: unwritablePath(t)})
+			warnings := entries(t, buf)
+			if len(warnings) != 1 || !strings.HasPrefix(fmt.Sprint(warnings[0]["msg"]), "log file is unwritable") {
+				t.Fatalf("entries %v, want the fallback warning", warnings)
+			}
+			l.Info("console line")
+			if got := entries(t, buf); len(got) != 1 {
+				t.Fatalf("console entries %v, want the logged line", got)
+			}
+		})
+	}
+}
+
+func TestEmptyFileLocation(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				l, err := NewLogger(Configuration{
+					EnableConsole: true,
+					ConsoleLevel:  infoLvl,
+					ConsoleWriter: &bytes.Buffer{},
+					EnableFile:    true,
+					FileLevel:     infoLvl,
+					StrictFile:    strict,
+				}, backend)
+				if !errors.Is(err, ErrFileLocationRequired) || l != nil {
+					t.Fatalf("NewLogger with StrictFile %v = (%v, %v), want ErrFileLocationRequired", strict, l, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUnwritableFileStrict(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, err := NewLogger(Configuration{
+				EnableConsole: true,
+				ConsoleWriter: &bytes.Buffer{},
+				EnableFile:    true,
+				FileLocation:  unwritablePath(t),
+				StrictFile:    true,
+			}, backend)
+			if err == nil || l != nil {
+				t.Fatalf("NewLogger = (%v, %v), want an error", l, err)
+			}
+		})
+	}
+}
+
+func TestPackageInfoMatchesInfof(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC) })
+	t.Cleanup(func() { SetClock(nil) })
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			resetGlobal(t)
+			buf := &bytes.Buffer{}
+			if _, err := InitLogger(Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: buf}, backend); err != nil {
+				t.Fatalf("InitLogger: %v", err)
+			}
+			Info("x")
+			Infof("x")
+			Error("x")
+			Errorf("x")
+			es := entries(t, buf)
+			if len(es) != 4 {
+				t.Fatalf("got %d entries, want 4", len(es))
+			}
+			// The caller, when logged, differs as the call sites are on different lines but must be this file.
+			for _, entry := range es {
+				if caller, ok := entry["caller"].(string); ok && !strings.HasPrefix(caller, "logger/logger_test.go:") {
+					t.Errorf("caller = %q, want this file", caller)
+				}
+				delete(entry, "caller")
+			}
+			if !reflect.DeepEqual(es[0], es[1]) || !reflect.DeepEqual(es[2], es[3]) {
+				t.Fatalf("entries %v, want Info like Infof and Error like Errorf", es)
+			}
+		})
+	}
+}
+
+func TestPackageInfoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts vary under the race detector")
+	}
+	resetGlobal(t)
+	if _, err := InitLogger(Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: io.Discard}, LoggerBackendZap); err != nil {
+		t.Fatalf("InitLogger: %v", err)
+	}
+	// Info must not box msg, it allocates no more than Infof without arguments which zap does not format.
+	info := testing.AllocsPerRun(100, func() { Info("x") })
+	infof := testing.AllocsPerRun(100, func() { Infof("x") })
+	if info > infof {
+		t.Fatalf("Info allocates %v times, Infof %v", info, infof)
+	}
+}
+
+func BenchmarkPackageInfo(b *testing.B) {
+	for name, backend := range backends {
+		resetGlobal(b)
+		if _, err := InitLogger(Configuration{EnableConsole: true, ConsoleJSONFormat: true, ConsoleLevel: infoLvl, ConsoleWriter: io.Discard}, backend); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name+"/Info", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Info("x")
+			}
+		})
+		b.Run(name+"/Infof", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Infof("x")
+			}
+		})
+	}
+}
+
+func TestIncludeHostnameAndPID(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("hostname: %v", err)
+	}
+	pid := float64(os.Getpid())
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{IncludeHostname: true, IncludePID: true})
+			child := l.WithFields(Fields{"request_id": "req-1"})
+			l.Info("first")
+			child.Warn("second")
+			l.Error("third")
+			es := entries(t, buf)
+			if len(es) != 3 {
+				t.Fatalf("got %d entries, want 3", len(es))
+			}
+			for _, entry := range es {
+				assertFields(t, entry, map[string]interface{}{"host": host, "pid": pid})
+			}
+
+			l, buf = newBufferLogger(t, backend, Configuration{})
+			assertFields(t, lastEntry(t, l, buf), nil, "host", "pid")
+		})
+	}
+}
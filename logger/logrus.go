@@ -1,32 +1,73 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
-	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+	"go.uber.org/multierr"
 )
 
 type logrusLogEntry struct {
-	entry *logrus.Entry
+	entry   *logrus.Entry
+	closers []io.Closer
+	// groups is the path of nested maps the fields are added to, see WithGroup.
+	groups []string
+	// humanizeBytes renders ByteSize fields as strings, see Configuration.HumanizeBytes.
+	humanizeBytes bool
 }
 
 type logrusLogger struct {
 	logger *logrus.Logger
+	// closers are the files opened by the logger, shared with the loggers built with WithFields.
+	closers       []io.Closer
+	humanizeBytes bool
 }
 
-func getFormatter(isJSON bool) logrus.Formatter {
+func getFormatter(isJSON bool, keys FieldKeys, color bool) logrus.Formatter {
 	if isJSON {
-		return &logrus.JSONFormatter{}
+		return &logrus.JSONFormatter{
+			FieldMap: getFieldMap(keys),
+		}
 	}
 	return &logrus.TextFormatter{
 		FullTimestamp:          true,
 		DisableLevelTruncation: true,
+		FieldMap:               getFieldMap(keys),
+		ForceColors:            color,
+		DisableColors:          !color,
 	}
 }
 
+func getFieldMap(keys FieldKeys) logrus.FieldMap {
+	fieldMap := logrus.FieldMap{}
+	if keys.TimeKey != "" {
+		fieldMap[logrus.FieldKeyTime] = keys.TimeKey
+	}
+	if keys.LevelKey != "" {
+		fieldMap[logrus.FieldKeyLevel] = keys.LevelKey
+	}
+	if keys.MessageKey != "" {
+		fieldMap[logrus.FieldKeyMsg] = keys.MessageKey
+	}
+	if keys.CallerKey != "" {
+		fieldMap[logrus.FieldKeyFile] = keys.CallerKey
+	}
+	return fieldMap
+}
+
 func newLogrusLogger(config Configuration) (Logger, error) {
+	fileErr := config.checkFileWritable()
+	if fileErr != nil {
+		if config.StrictFile {
+			return nil, fileErr
+		}
+		config.EnableFile = false
+		config.EnableConsole = true
+	}
+
 	logLevel := config.ConsoleLevel
 	if logLevel == "" {
 		logLevel = config.FileLevel
@@ -37,37 +78,173 @@ func newLogrusLogger(config Configuration) (Logger, error) {
 		return nil, err
 	}
 
-	stdOutHandler := os.Stdout
-	fileHandler := &lumberjack.Logger{
-		Filename: config.FileLocation,
-		MaxSize:  100,
-		Compress: true,
-		MaxAge:   28,
-	}
+	consoleOut := config.consoleWriter()
 	lLogger := &logrus.Logger{
-		Out:       stdOutHandler,
-		Formatter: getFormatter(config.ConsoleJSONFormat),
+		Out:       consoleOut,
+		Formatter: getFormatter(config.ConsoleJSONFormat, config.FieldKeys, config.consoleColored()),
 		Hooks:     make(logrus.LevelHooks),
 		Level:     level,
 	}
+	// Added first so that the other hooks see the evaluated values and the time of the clock.
+	lLogger.AddHook(clockHook{})
+	lLogger.AddHook(lazyHook{})
+
+	// The fatal entry may still be queued by the async writer or the file buffer, flush it before leaving.
+	lLogger.ExitFunc = func(code int) {
+		_ = syncLogrus(lLogger)
+		if config.FatalPanics {
+			panic(fmt.Sprintf("logrus: fatal exit with code %d", code))
+		}
+		exitFunc(code)
+	}
 
-	if config.EnableConsole && config.EnableFile {
-		lLogger.SetOutput(io.MultiWriter(stdOutHandler, fileHandler))
-	} else if config.EnableFile {
-		lLogger.SetOutput(fileHandler)
-		lLogger.SetFormatter(getFormatter(config.FileJSONFormat))
+	// When both are enabled, the console is the output and the file is written by a hook with its own format.
+	var closers []io.Closer
+	var fileHookWriter io.Writer
+	outIsConsole := true
+	if config.EnableFile {
+		fileOut, fileClosers := config.fileOutput()
+		closers = fileClosers
+		fileWriter := fileOut
+		if config.FileBufferSize > 0 {
+			fileWriter = newBufferedWriter(fileOut, config.FileBufferSize)
+		}
+		if config.EnableConsole {
+			fileHookWriter = fileWriter
+		} else {
+			lLogger.SetOutput(fileWriter)
+			lLogger.SetFormatter(getFormatter(config.FileJSONFormat, config.FieldKeys, false))
+			outIsConsole = false
+		}
+	}
+	if config.Async {
+		out := lLogger.Out
+		if outIsConsole {
+			// Hide Sync of the console, logrus never syncs it.
+			out = struct{ io.Writer }{out}
+		}
+		asyncOut := newAsyncWriter(out, config.AsyncBufferSize)
+		lLogger.SetOutput(asyncOut)
+		// The async writers are closed before the files they write to.
+		asyncClosers := []io.Closer{asyncOut}
+		if fileHookWriter != nil {
+			asyncHook := newAsyncWriter(fileHookWriter, config.AsyncBufferSize)
+			fileHookWriter = asyncHook
+			asyncClosers = append(asyncClosers, asyncHook)
+		}
+		closers = append(asyncClosers, closers...)
+	}
+
+	if config.StacktraceLevel != "" {
+		stacktraceLevel, err := logrus.ParseLevel(config.StacktraceLevel)
+		if err != nil {
+			return nil, err
+		}
+		lLogger.AddHook(stacktraceHook{level: stacktraceLevel})
+	}
+
+	if fileErr != nil {
+		lLogger.Warnf("log file is unwritable, falling back to console: %v", fileErr)
+	}
+	if config.EnableSyslog {
+		hook, err := newSyslogHook(config)
+		if err != nil {
+			// Out is already the console unless only file logging is enabled.
+			if config.EnableFile && !config.EnableConsole {
+				lLogger.SetOutput(io.MultiWriter(lLogger.Out, consoleOut))
+			}
+			lLogger.Warnf("syslog is unavailable, falling back to console: %v", err)
+		} else {
+			lLogger.AddHook(hook)
+		}
+	}
+
+	// Added last so that the file gets the fields added by the other hooks.
+	if fileHookWriter != nil {
+		lLogger.AddHook(&fileHook{
+			writer:    fileHookWriter,
+			formatter: getFormatter(config.FileJSONFormat, config.FieldKeys, false),
+		})
 	}
 
 	return &logrusLogger{
-		logger: lLogger,
+		logger:        lLogger,
+		closers:       closers,
+		humanizeBytes: config.HumanizeBytes,
 	}, nil
 }
 
+// lazyHook evaluates the LazyValue fields, hooks only fire for entries which are emitted.
+type lazyHook struct{}
+
+func (lazyHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (lazyHook) Fire(entry *logrus.Entry) error {
+	for k, v := range entry.Data {
+		if resolved, ok := resolveLazy(v); ok {
+			entry.Data[k] = resolved
+		}
+	}
+	return nil
+}
+
+// resolveLazy evaluates v when it is a LazyValue, or the LazyValue fields of v when it is a group.
+// Groups are shared by the entries of a logger, so they are copied rather than modified.
+func resolveLazy(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case LazyValue:
+		return convertToLogrusValue(val.Value()), true
+	case map[string]interface{}:
+		var resolved map[string]interface{}
+		for k, nested := range val {
+			r, ok := resolveLazy(nested)
+			if !ok {
+				continue
+			}
+			if resolved == nil {
+				resolved = make(map[string]interface{}, len(val))
+				for k2, v2 := range val {
+					resolved[k2] = v2
+				}
+			}
+			resolved[k] = r
+		}
+		return resolved, resolved != nil
+	default:
+		return v, false
+	}
+}
+
+// fileHook writes every entry to writer with its own formatter, independently of the logger output.
+type fileHook struct {
+	writer    io.Writer
+	formatter logrus.Formatter
+}
+
+func (h *fileHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *fileHook) Fire(entry *logrus.Entry) error {
+	b, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(b)
+	return err
+}
+
 func (l *logrusLogger) Debugf(format string, args ...interface{}) {
 	l.logger.Debugf(format, args...)
 }
 
+// Debug returns early when the level is disabled, so that msg is not boxed into the variadic arguments of logrus.
 func (l *logrusLogger) Debug(msg string) {
+	if !l.logger.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
 	l.logger.Debug(msg)
 }
 
@@ -76,6 +253,9 @@ func (l *logrusLogger) Infof(format string, args ...interface{}) {
 }
 
 func (l *logrusLogger) Info(msg string) {
+	if !l.logger.IsLevelEnabled(logrus.InfoLevel) {
+		return
+	}
 	l.logger.Info(msg)
 }
 
@@ -84,6 +264,9 @@ func (l *logrusLogger) Warnf(format string, args ...interface{}) {
 }
 
 func (l *logrusLogger) Warn(msg string) {
+	if !l.logger.IsLevelEnabled(logrus.WarnLevel) {
+		return
+	}
 	l.logger.Warn(msg)
 }
 
@@ -92,6 +275,9 @@ func (l *logrusLogger) Errorf(format string, args ...interface{}) {
 }
 
 func (l *logrusLogger) Error(msg string) {
+	if !l.logger.IsLevelEnabled(logrus.ErrorLevel) {
+		return
+	}
 	l.logger.Error(msg)
 }
 
@@ -111,15 +297,39 @@ func (l *logrusLogger) Panic(msg string) {
 	l.logger.Panic(msg)
 }
 func (l *logrusLogger) Sync() error {
-	return nil
+	return syncLogrus(l.logger)
+}
+
+func (l *logrusLogger) Close() error {
+	return closeAll(l.Sync, l.closers)
+}
+
+func (l *logrusLogger) Rotate() error {
+	return rotateAll(l.Sync, l.closers)
+}
+
+func (l *logrusLogger) SyncContext(ctx context.Context) error {
+	return syncContext(ctx, l.Sync)
 }
 
 func (l *logrusLogger) WithFields(fields Fields) Logger {
 	return &logrusLogEntry{
-		entry: l.logger.WithFields(convertToLogrusFields(fields)),
+		entry:         l.logger.WithFields(convertToLogrusFields(fields, l.humanizeBytes)),
+		closers:       l.closers,
+		humanizeBytes: l.humanizeBytes,
 	}
 }
 
+func (l *logrusLogger) WithAttrs(fields ...Field) Logger {
+	return l.WithFields(NewFields(fields...))
+}
+
+// WithGroup returns a logger whose subsequent fields are nested in a map under name.
+func (l *logrusLogger) WithGroup(name string) Logger {
+	entry := &logrusLogEntry{entry: logrus.NewEntry(l.logger), closers: l.closers, humanizeBytes: l.humanizeBytes}
+	return entry.WithGroup(name)
+}
+
 func (l *logrusLogger) GetDelegate() interface{} {
 	return l.logger
 }
@@ -129,6 +339,9 @@ func (l *logrusLogEntry) Debugf(format string, args ...interface{}) {
 }
 
 func (l *logrusLogEntry) Debug(msg string) {
+	if !l.entry.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
 	l.entry.Debug(msg)
 }
 
@@ -137,6 +350,9 @@ func (l *logrusLogEntry) Infof(format string, args ...interface{}) {
 }
 
 func (l *logrusLogEntry) Info(msg string) {
+	if !l.entry.Logger.IsLevelEnabled(logrus.InfoLevel) {
+		return
+	}
 	l.entry.Info(msg)
 }
 
@@ -145,6 +361,9 @@ func (l *logrusLogEntry) Warnf(format string, args ...interface{}) {
 }
 
 func (l *logrusLogEntry) Warn(msg string) {
+	if !l.entry.Logger.IsLevelEnabled(logrus.WarnLevel) {
+		return
+	}
 	l.entry.Warn(msg)
 }
 
@@ -153,6 +372,9 @@ func (l *logrusLogEntry) Errorf(format string, args ...interface{}) {
 }
 
 func (l *logrusLogEntry) Error(msg string) {
+	if !l.entry.Logger.IsLevelEnabled(logrus.ErrorLevel) {
+		return
+	}
 	l.entry.Error(msg)
 }
 
@@ -173,23 +395,132 @@ func (l *logrusLogEntry) Panic(msg string) {
 }
 
 func (l *logrusLogEntry) Sync() error {
-	return nil
+	return syncLogrus(l.entry.Logger)
+}
+
+func (l *logrusLogEntry) Close() error {
+	return closeAll(l.Sync, l.closers)
+}
+
+func (l *logrusLogEntry) Rotate() error {
+	return rotateAll(l.Sync, l.closers)
+}
+
+func (l *logrusLogEntry) SyncContext(ctx context.Context) error {
+	return syncContext(ctx, l.Sync)
 }
 
 func (l *logrusLogEntry) WithFields(fields Fields) Logger {
+	data := convertToLogrusFields(fields, l.humanizeBytes)
+	if len(l.groups) > 0 {
+		data = logrus.Fields{l.groups[0]: nestFields(l.entry.Data[l.groups[0]], l.groups[1:], data)}
+	}
 	return &logrusLogEntry{
-		entry: l.entry.WithFields(convertToLogrusFields(fields)),
+		entry:         l.entry.WithFields(data),
+		closers:       l.closers,
+		groups:        l.groups,
+		humanizeBytes: l.humanizeBytes,
 	}
 }
 
+// WithGroup returns a logger whose subsequent fields are nested in a map under name.
+func (l *logrusLogEntry) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	return &logrusLogEntry{
+		entry:         l.entry,
+		closers:       l.closers,
+		groups:        append(append(make([]string, 0, len(l.groups)+1), l.groups...), name),
+		humanizeBytes: l.humanizeBytes,
+	}
+}
+
+// nestFields returns a copy of the group existing with fields added at the end of the groups path.
+func nestFields(existing interface{}, groups []string, fields logrus.Fields) map[string]interface{} {
+	old, _ := existing.(map[string]interface{})
+	group := make(map[string]interface{}, len(old)+len(fields))
+	for k, v := range old {
+		group[k] = v
+	}
+	if len(groups) == 0 {
+		for k, v := range fields {
+			group[k] = v
+		}
+		return group
+	}
+	group[groups[0]] = nestFields(group[groups[0]], groups[1:], fields)
+	return group
+}
+
+func (l *logrusLogEntry) WithAttrs(fields ...Field) Logger {
+	return l.WithFields(NewFields(fields...))
+}
+
 func (l *logrusLogEntry) GetDelegate() interface{} {
 	return l.entry
 }
 
-func convertToLogrusFields(fields Fields) logrus.Fields {
+// syncLogrus drains the async and buffered writers of l, the output and the one of the file hook.
+func syncLogrus(l *logrus.Logger) error {
+	err := syncOwnWriter(l.Out)
+	// The file hook fires on all levels, so it is registered for the panic level too.
+	for _, hook := range l.Hooks[logrus.PanicLevel] {
+		if h, ok := hook.(*fileHook); ok {
+			err = multierr.Append(err, syncOwnWriter(h.writer))
+		}
+	}
+	return err
+}
+
+// syncOwnWriter syncs w when it is one of the writers of this package, stdout and files are never synced by logrus.
+func syncOwnWriter(w io.Writer) error {
+	switch v := w.(type) {
+	case *asyncWriter:
+		return v.Sync()
+	case *bufferedWriter:
+		return v.Sync()
+	default:
+		return nil
+	}
+}
+
+// convertToLogrusValue renders durations and times as strings, logrus would otherwise encode
+// durations as nanoseconds.
+func convertToLogrusValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case ByteSize:
+		return int64(v)
+	default:
+		return val
+	}
+}
+
+func convertToLogrusFields(fields Fields, humanizeBytes bool) logrus.Fields {
 	logrusFields := logrus.Fields{}
 	for index, val := range fields {
-		logrusFields[index] = val
+		if b, ok := val.(ByteSize); ok {
+			logrusFields[index] = byteSizeValue(b, humanizeBytes)
+			continue
+		}
+		logrusFields[index] = convertToLogrusValue(val)
 	}
 	return logrusFields
 }
+
+// LogrusDelegate returns the logrus logger behind l, false when l is not backed by logrus.
+// For a logger returned by WithFields, it is the underlying logger, without the fields.
+func LogrusDelegate(l Logger) (*logrus.Logger, bool) {
+	switch v := l.GetDelegate().(type) {
+	case *logrus.Logger:
+		return v, true
+	case *logrus.Entry:
+		return v.Logger, true
+	default:
+		return nil, false
+	}
+}
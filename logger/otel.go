@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var enableOTELTraceOnce sync.Once
+
+// EnableOTELTrace makes WithContext attach the trace_id and span_id of the OpenTelemetry span of the context,
+// when it is valid. Calling it more than once has no effect.
+func EnableOTELTrace() {
+	enableOTELTraceOnce.Do(func() {
+		RegisterContextExtractor(otelTraceFields)
+	})
+}
+
+func otelTraceFields(ctx context.Context) Fields {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+	return Fields{
+		"trace_id": spanCtx.TraceID().String(),
+		"span_id":  spanCtx.SpanID().String(),
+	}
+}
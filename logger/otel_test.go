@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEnableOTELTrace(t *testing.T) {
+	EnableOTELTrace()
+	EnableOTELTrace()
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			ctx := ToContext(context.Background(), l)
+
+			entry := lastEntry(t, WithContext(trace.ContextWithSpanContext(ctx, spanCtx)), buf)
+			assertFields(t, entry, map[string]interface{}{
+				"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id":  "00f067aa0ba902b7",
+			})
+			assertFields(t, lastEntry(t, WithContext(ctx), buf), nil, "trace_id", "span_id")
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package logger
+
+// raceEnabled reports whether the race detector is on, it makes allocation counts unreliable.
+const raceEnabled = true
@@ -0,0 +1,258 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitShards = 16
+	// rateLimitMaxShardSize is the number of lines a shard tracks at most, lines beyond it are never suppressed.
+	rateLimitMaxShardSize = 1024
+)
+
+type rateLimitState struct {
+	last       time.Time
+	suppressed int
+	// msg and write are the message and the write func of the last suppressed duplicate, to report them.
+	msg   string
+	write func(string)
+}
+
+type rateLimitShard struct {
+	mu   sync.Mutex
+	seen map[uint64]*rateLimitState
+}
+
+type suppressedLine struct {
+	msg   string
+	count int
+	write func(string)
+}
+
+// rateLimiter remembers when each (level, message) pair was last emitted.
+// It is sharded by message hash so that concurrent loggers rarely contend on the same lock.
+type rateLimiter struct {
+	every   time.Duration
+	now     func() time.Time
+	shards  [rateLimitShards]rateLimitShard
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// allow reports whether the line may be emitted and how many duplicates were suppressed since the last emitted one.
+func (r *rateLimiter) allow(level, msg string, write func(string)) (bool, int) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(level))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(msg))
+	key := h.Sum64()
+
+	shard := &r.shards[key%rateLimitShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := r.now()
+	state, ok := shard.seen[key]
+	if ok && now.Sub(state.last) < r.every {
+		state.suppressed++
+		state.msg, state.write = msg, write
+		return false, 0
+	}
+	if !ok {
+		if len(shard.seen) >= rateLimitMaxShardSize {
+			// Tracking more lines would grow the shard without bound, emit the line untracked.
+			return true, 0
+		}
+		state = &rateLimitState{}
+		shard.seen[key] = state
+	}
+	suppressed := state.suppressed
+	state.last, state.suppressed, state.write = now, 0, nil
+	return true, suppressed
+}
+
+// flush returns the suppressed duplicates of the lines whose window is over, or of every line when all is set,
+// and forgets these lines.
+func (r *rateLimiter) flush(all bool) []suppressedLine {
+	var lines []suppressedLine
+	now := r.now()
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.Lock()
+		for key, state := range shard.seen {
+			if !all && now.Sub(state.last) < r.every {
+				continue
+			}
+			if state.suppressed > 0 {
+				lines = append(lines, suppressedLine{msg: state.msg, count: state.suppressed, write: state.write})
+			}
+			delete(shard.seen, key)
+		}
+		shard.mu.Unlock()
+	}
+	return lines
+}
+
+// run reports the suppressed duplicates every r.every until close.
+func (r *rateLimiter) run() {
+	defer close(r.stopped)
+	ticker := time.NewTicker(r.every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.report(false)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// report writes the suppressed duplicates returned by flush.
+func (r *rateLimiter) report(all bool) {
+	for _, line := range r.flush(all) {
+		line.write(suppressedMessage(line.msg, line.count))
+	}
+}
+
+// close stops run, waits for it to return and reports the pending suppressed duplicates.
+func (r *rateLimiter) close() {
+	r.once.Do(func() {
+		close(r.stop)
+		if r.every > 0 {
+			<-r.stopped
+		}
+		r.report(true)
+	})
+}
+
+func suppressedMessage(msg string, count int) string {
+	return fmt.Sprintf("%s ... suppressed %d duplicates", msg, count)
+}
+
+type rateLimitedLogger struct {
+	logger  Logger
+	limiter *rateLimiter
+}
+
+// RateLimited returns a Logger wrapping the global logger which emits identical lines (same level and message)
+// at most once per every. The number of suppressed duplicates is reported in a "... suppressed N duplicates" line,
+// when the line is emitted again or at the latest one period after its window is over.
+// Fatal and Panic lines are never suppressed. Fields do not take part in the comparison.
+// The report runs in a goroutine which Close stops, after reporting the pending duplicates; Close must be called
+// once the logger is no longer used. It leaves the global logger open. The loggers derived with WithFields
+// share the report.
+func RateLimited(every time.Duration) Logger {
+	return newRateLimitedLogger(global(), every, time.Now)
+}
+
+func newRateLimitedLogger(l Logger, every time.Duration, clock func() time.Time) *rateLimitedLogger {
+	limiter := &rateLimiter{every: every, now: clock, stop: make(chan struct{}), stopped: make(chan struct{})}
+	for i := range limiter.shards {
+		limiter.shards[i].seen = make(map[uint64]*rateLimitState)
+	}
+	if every > 0 {
+		go limiter.run()
+	}
+	return &rateLimitedLogger{logger: l, limiter: limiter}
+}
+
+func (l *rateLimitedLogger) emit(level, msg string, write func(string)) {
+	ok, suppressed := l.limiter.allow(level, msg, write)
+	if !ok {
+		return
+	}
+	write(msg)
+	if suppressed > 0 {
+		write(suppressedMessage(msg, suppressed))
+	}
+}
+
+func (l *rateLimitedLogger) Debugf(format string, args ...interface{}) {
+	l.emit(debugLvl, fmt.Sprintf(format, args...), l.logger.Debug)
+}
+
+func (l *rateLimitedLogger) Debug(msg string) {
+	l.emit(debugLvl, msg, l.logger.Debug)
+}
+
+func (l *rateLimitedLogger) Infof(format string, args ...interface{}) {
+	l.emit(infoLvl, fmt.Sprintf(format, args...), l.logger.Info)
+}
+
+func (l *rateLimitedLogger) Info(msg string) {
+	l.emit(infoLvl, msg, l.logger.Info)
+}
+
+func (l *rateLimitedLogger) Warnf(format string, args ...interface{}) {
+	l.emit(warnLvl, fmt.Sprintf(format, args...), l.logger.Warn)
+}
+
+func (l *rateLimitedLogger) Warn(msg string) {
+	l.emit(warnLvl, msg, l.logger.Warn)
+}
+
+func (l *rateLimitedLogger) Errorf(format string, args ...interface{}) {
+	l.emit(errorLvl, fmt.Sprintf(format, args...), l.logger.Error)
+}
+
+func (l *rateLimitedLogger) Error(msg string) {
+	l.emit(errorLvl, msg, l.logger.Error)
+}
+
+func (l *rateLimitedLogger) Fatalf(format string, args ...interface{}) {
+	l.logger.Fatalf(format, args...)
+}
+
+func (l *rateLimitedLogger) Fatal(msg string) {
+	l.logger.Fatal(msg)
+}
+
+func (l *rateLimitedLogger) Panicf(format string, args ...interface{}) {
+	l.logger.Panicf(format, args...)
+}
+
+func (l *rateLimitedLogger) Panic(msg string) {
+	l.logger.Panic(msg)
+}
+
+func (l *rateLimitedLogger) Sync() error {
+	return l.logger.Sync()
+}
+
+// Close stops the periodic report of l and of the loggers derived from it, the wrapped logger is left open.
+func (l *rateLimitedLogger) Close() error {
+	l.limiter.close()
+	return nil
+}
+
+func (l *rateLimitedLogger) Rotate() error {
+	return l.logger.Rotate()
+}
+
+func (l *rateLimitedLogger) SyncContext(ctx context.Context) error {
+	return l.logger.SyncContext(ctx)
+}
+
+// WithFields returns a rate limited logger sharing the limiter of l.
+func (l *rateLimitedLogger) WithFields(fields Fields) Logger {
+	return &rateLimitedLogger{logger: l.logger.WithFields(fields), limiter: l.limiter}
+}
+
+func (l *rateLimitedLogger) WithAttrs(fields ...Field) Logger {
+	return l.WithFields(NewFields(fields...))
+}
+
+// WithGroup returns a rate limited logger sharing the limiter of l.
+func (l *rateLimitedLogger) WithGroup(name string) Logger {
+	return &rateLimitedLogger{logger: l.logger.WithGroup(name), limiter: l.limiter}
+}
+
+func (l *rateLimitedLogger) GetDelegate() interface{} {
+	return l.logger.GetDelegate()
+}
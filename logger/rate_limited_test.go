@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock advanced by the test.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func messages(c *JSONCapture) []string {
+	var msgs []string
+	for _, e := range c.Entries() {
+		msgs = append(msgs, e["msg"].(string))
+	}
+	return msgs
+}
+
+func TestRateLimitedSuppressesDuplicates(t *testing.T) {
+	capture := NewJSONCapture()
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := newRateLimitedLogger(capture, time.Hour, clock.now)
+	defer l.limiter.close()
+
+	for i := 0; i < 1000; i++ {
+		l.Error("dependency down")
+	}
+	l.Warn("dependency down")
+	if got := messages(capture); len(got) != 2 {
+		t.Fatalf("emitted %v, want the error and the warning once", got)
+	}
+
+	clock.add(time.Hour)
+	l.Error("dependency down")
+	got := messages(capture)
+	want := []string{"dependency down", "dependency down", "dependency down", "dependency down ... suppressed 999 duplicates"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("emitted %q, want %q", got, want)
+	}
+}
+
+func TestRateLimitedFlushReportsStoppedDuplicates(t *testing.T) {
+	capture := NewJSONCapture()
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := newRateLimitedLogger(capture, time.Hour, clock.now)
+	defer l.limiter.close()
+
+	for i := 0; i < 5; i++ {
+		l.WithFields(Fields{"attempt": i}).Error("flapping")
+	}
+	l.Info("once")
+	if lines := l.limiter.flush(false); len(lines) != 0 {
+		t.Fatalf("flushed %v within the window", lines)
+	}
+
+	clock.add(time.Hour)
+	lines := l.limiter.flush(false)
+	if len(lines) != 1 || lines[0].msg != "flapping" || lines[0].count != 4 {
+		t.Fatalf("flushed %+v, want 4 duplicates of flapping", lines)
+	}
+	lines[0].write(suppressedMessage(lines[0].msg, lines[0].count))
+	entries := capture.Entries()
+	last := entries[len(entries)-1]
+	if last["msg"] != "flapping ... suppressed 4 duplicates" || last["level"] != "error" || last["attempt"] != float64(4) {
+		t.Fatalf("summary entry = %v", last)
+	}
+	for i := range l.limiter.shards {
+		if n := len(l.limiter.shards[i].seen); n != 0 {
+			t.Fatalf("shard %d keeps %d expired lines", i, n)
+		}
+	}
+}
+
+func TestRateLimitedPeriodicSummary(t *testing.T) {
+	capture := NewJSONCapture()
+	l := newRateLimitedLogger(capture, 20*time.Millisecond, time.Now)
+	defer l.Close()
+
+	for i := 0; i < 10; i++ {
+		l.Error("burst")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		msgs := messages(capture)
+		if len(msgs) == 2 && msgs[1] == "burst ... suppressed 9 duplicates" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("emitted %q, want the periodic summary", msgs)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// closeRecorder records whether the logger it wraps was closed.
+type closeRecorder struct {
+	Logger
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRateLimitedClose(t *testing.T) {
+	capture := NewJSONCapture()
+	wrapped := &closeRecorder{Logger: capture}
+	l := newRateLimitedLogger(wrapped, time.Hour, time.Now)
+	for i := 0; i < 3; i++ {
+		l.Error("burst")
+	}
+	if err := l.WithFields(Fields{"k": "v"}).Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case <-l.limiter.stopped:
+	default:
+		t.Fatal("periodic report still running after Close")
+	}
+	if wrapped.closed {
+		t.Fatal("Close closed the wrapped logger")
+	}
+	// The pending duplicates are reported on Close, the wrapped logger still writes afterwards.
+	l.Error("after close")
+	want := []string{"burst", "burst ... suppressed 2 duplicates", "after close"}
+	if got := messages(capture); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("emitted %q, want %q", got, want)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestRateLimitedShardsAreBounded(t *testing.T) {
+	capture := NewJSONCapture()
+	l := newRateLimitedLogger(capture, time.Hour, (&fakeClock{t: time.Unix(1000, 0)}).now)
+	defer l.limiter.close()
+
+	n := 2 * rateLimitShards * rateLimitMaxShardSize
+	for i := 0; i < n; i++ {
+		l.limiter.allow(errorLvl, "line "+strconv.Itoa(i), capture.Error)
+	}
+	for i := range l.limiter.shards {
+		if size := len(l.limiter.shards[i].seen); size > rateLimitMaxShardSize {
+			t.Fatalf("shard %d tracks %d lines, more than %d", i, size, rateLimitMaxShardSize)
+		}
+	}
+	// Every shard is full, untracked lines are never suppressed.
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.limiter.allow(errorLvl, "untracked", capture.Error); !ok {
+			t.Fatal("untracked line suppressed")
+		}
+	}
+}
+
+func TestRateLimitedNeverSuppressesPanic(t *testing.T) {
+	capture := NewJSONCapture()
+	l := newRateLimitedLogger(capture, time.Hour, time.Now)
+	defer l.limiter.close()
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() { _ = recover() }()
+			l.Panic("boom")
+		}()
+	}
+	if got := messages(capture); len(got) != 2 {
+		t.Fatalf("emitted %v, want both panics", got)
+	}
+}
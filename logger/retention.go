@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	defaultFileMaxAgeDays = 28
+	// archiveTimeFormat is the timestamp lumberjack puts in the name of rotated files, in UTC.
+	archiveTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// newFileHandler returns the rotating writer of FileLocation.
+func (c Configuration) newFileHandler() *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename: c.FileLocation,
+		MaxSize:  100,
+		Compress: c.FileCompress == nil || *c.FileCompress,
+		MaxAge:   c.fileMaxAgeDays(),
+	}
+}
+
+// fileOutput returns the writer of the file output and the closers it owns: FileWriter when set, which is left
+// open, the rotating writer of FileLocation and its retention routine otherwise.
+func (c Configuration) fileOutput() (io.Writer, []io.Closer) {
+	if c.FileWriter != nil {
+		return c.FileWriter, nil
+	}
+	fileHandler := c.newFileHandler()
+	closers := []io.Closer{fileHandler}
+	if r := c.newRetention(); r != nil {
+		closers = append(closers, r)
+	}
+	return fileHandler, closers
+}
+
+// fileMaxAgeDays returns the number of days rotated files are kept, 0 to keep them forever.
+func (c Configuration) fileMaxAgeDays() int {
+	switch {
+	case c.FileMaxAgeDays > 0:
+		return c.FileMaxAgeDays
+	case c.FileMaxAgeDays < 0:
+		return 0
+	default:
+		return defaultFileMaxAgeDays
+	}
+}
+
+// newRetention starts the retention routine of FileLocation when FileRetentionInterval is set and rotated files
+// are not kept forever, nil otherwise.
+func (c Configuration) newRetention() *retention {
+	if c.FileRetentionInterval <= 0 || c.fileMaxAgeDays() == 0 {
+		return nil
+	}
+	maxAge := time.Duration(c.fileMaxAgeDays()) * 24 * time.Hour
+	return startRetention(c.FileLocation, maxAge, c.FileRetentionInterval)
+}
+
+// retention removes the archives of a log file older than maxAge on every tick, lumberjack only does it on rotation.
+type retention struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func startRetention(location string, maxAge, interval time.Duration) *retention {
+	r := &retention{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				// Failures are retried on the next tick, there is no logger to report them to.
+				_ = removeStaleArchives(location, maxAge, now)
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// Close stops the routine and waits for the running removal to finish.
+func (r *retention) Close() error {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+	return nil
+}
+
+// removeStaleArchives removes the rotated files of location, compressed or not, whose timestamp is older than maxAge.
+func removeStaleArchives(location string, maxAge time.Duration, now time.Time) error {
+	dir := filepath.Dir(location)
+	base := filepath.Base(location)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-maxAge)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".gz")
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		t, parseErr := time.Parse(archiveTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if parseErr != nil || !t.Before(cutoff) {
+			continue
+		}
+		err = multierr.Append(err, os.Remove(filepath.Join(dir, entry.Name())))
+	}
+	return err
+}
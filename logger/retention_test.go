@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeArchives creates empty files named in dir.
+func writeArchives(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// archiveName returns the name lumberjack gives to the archive of app.log rotated at t.
+func archiveName(t time.Time) string {
+	return "app-" + t.UTC().Format(archiveTimeFormat) + ".log"
+}
+
+// dirNames returns the sorted names of the files of dir.
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRemoveStaleArchives(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	stale, staleGz := archiveName(now.Add(-8*24*time.Hour)), archiveName(now.Add(-30*24*time.Hour))+".gz"
+	recent, recentGz := archiveName(now.Add(-24*time.Hour)), archiveName(now.Add(-6*24*time.Hour))+".gz"
+	writeArchives(t, dir, "app.log", stale, staleGz, recent, recentGz, "other-2020-01-01T00-00-00.000.log", "app-notes.log")
+
+	if err := removeStaleArchives(filepath.Join(dir, "app.log"), 7*24*time.Hour, now); err != nil {
+		t.Fatalf("removeStaleArchives: %v", err)
+	}
+	want := []string{"app-notes.log", recent, recentGz, "app.log", "other-2020-01-01T00-00-00.000.log"}
+	sort.Strings(want)
+	if got := dirNames(t, dir); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("files %v, want %v", got, want)
+	}
+}
+
+func TestRetentionRoutine(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	stale, recent := archiveName(now.Add(-8*24*time.Hour))+".gz", archiveName(now.Add(-time.Hour))+".gz"
+	writeArchives(t, dir, stale, recent)
+
+	l, err := NewLogger(Configuration{
+		EnableFile:            true,
+		FileLevel:             infoLvl,
+		FileLocation:          filepath.Join(dir, "app.log"),
+		FileMaxAgeDays:        7,
+		FileRetentionInterval: 10 * time.Millisecond,
+	}, LoggerBackendZap)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	defer l.Close()
+
+	// Without any rotation, the stale archive is removed by the routine.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(filepath.Join(dir, stale)); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stale archive not removed, files %v", dirNames(t, dir))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, recent)); err != nil {
+		t.Fatalf("recent archive: %v", err)
+	}
+}
+
+func TestRotatedFilesCompressed(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLogger(Configuration{EnableFile: true, FileLevel: infoLvl, FileLocation: filepath.Join(dir, "app.log")}, LoggerBackendZap)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	l.Info("archived line")
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	// lumberjack compresses in the background, the archive is only complete once the uncompressed one is gone.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		names := dirNames(t, dir)
+		if len(names) == 2 && strings.HasSuffix(names[0], ".log.gz") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("files %v, want app.log and a compressed archive", names)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ring holds the most recent lines, shared by a RingBuffer and the loggers built from it with WithFields.
+type ring struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func (r *ring) add(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ring) dump() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	return append(append(make([]string, 0, len(r.lines)), r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// RingBuffer is a Logger keeping the last lines in memory, e.g. to dump them when recovering a panic.
+// Every level is recorded regardless of the level of the forwarded logger.
+type RingBuffer struct {
+	ring   *ring
+	fields Fields
+	// group is the dotted prefix of the fields added from now on, see WithGroup.
+	group   string
+	forward Logger
+	now     func() time.Time
+}
+
+// NewRingBuffer returns a RingBuffer keeping the n most recent lines.
+func NewRingBuffer(n int) *RingBuffer {
+	return WrapRingBuffer(nil, n)
+}
+
+// WrapRingBuffer returns a RingBuffer keeping the n most recent lines and forwarding every call to l.
+func WrapRingBuffer(l Logger, n int) *RingBuffer {
+	if n < 1 {
+		n = 1
+	}
+	return &RingBuffer{
+		ring:    &ring{lines: make([]string, n)},
+		forward: l,
+		now:     time.Now,
+	}
+}
+
+// Dump returns the recorded lines, from the oldest to the most recent.
+func (r *RingBuffer) Dump() []string {
+	return r.ring.dump()
+}
+
+// record formats and stores a line, "<time> <level> <msg> key=value...", keys sorted.
+func (r *RingBuffer) record(level, msg string) {
+	var sb strings.Builder
+	sb.WriteString(r.now().Format(time.RFC3339Nano))
+	sb.WriteByte(' ')
+	sb.WriteString(level)
+	sb.WriteByte(' ')
+	sb.WriteString(msg)
+	keys := make([]string, 0, len(r.fields))
+	for k := range r.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, r.fields[k])
+	}
+	r.ring.add(sb.String())
+}
+
+func (r *RingBuffer) Debugf(format string, args ...interface{}) {
+	r.Debug(fmt.Sprintf(format, args...))
+}
+
+func (r *RingBuffer) Debug(msg string) {
+	r.record(debugLvl, msg)
+	if r.forward != nil {
+		r.forward.Debug(msg)
+	}
+}
+
+func (r *RingBuffer) Infof(format string, args ...interface{}) {
+	r.Info(fmt.Sprintf(format, args...))
+}
+
+func (r *RingBuffer) Info(msg string) {
+	r.record(infoLvl, msg)
+	if r.forward != nil {
+		r.forward.Info(msg)
+	}
+}
+
+func (r *RingBuffer) Warnf(format string, args ...interface{}) {
+	r.Warn(fmt.Sprintf(format, args...))
+}
+
+func (r *RingBuffer) Warn(msg string) {
+	r.record(warnLvl, msg)
+	if r.forward != nil {
+		r.forward.Warn(msg)
+	}
+}
+
+func (r *RingBuffer) Errorf(format string, args ...interface{}) {
+	r.Error(fmt.Sprintf(format, args...))
+}
+
+func (r *RingBuffer) Error(msg string) {
+	r.record(errorLvl, msg)
+	if r.forward != nil {
+		r.forward.Error(msg)
+	}
+}
+
+func (r *RingBuffer) Fatalf(format string, args ...interface{}) {
+	r.Fatal(fmt.Sprintf(format, args...))
+}
+
+// Fatal records msg then forwards it, or exits with status 1 when there is no forwarded logger.
+func (r *RingBuffer) Fatal(msg string) {
+	r.record(fatalLvl, msg)
+	if r.forward != nil {
+		r.forward.Fatal(msg)
+		return
+	}
+	exitFunc(1)
+}
+
+func (r *RingBuffer) Panicf(format string, args ...interface{}) {
+	r.Panic(fmt.Sprintf(format, args...))
+}
+
+// Panic records msg then forwards it, or panics with msg when there is no forwarded logger.
+func (r *RingBuffer) Panic(msg string) {
+	r.record(panicLvl, msg)
+	if r.forward != nil {
+		r.forward.Panic(msg)
+		return
+	}
+	panic(msg)
+}
+
+// WithFields returns a RingBuffer sharing the lines of r.
+func (r *RingBuffer) WithFields(fields Fields) Logger {
+	merged := make(Fields, len(r.fields)+len(fields))
+	for k, v := range r.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		if r.group != "" {
+			k = r.group + "." + k
+		}
+		merged[k] = v
+	}
+	child := &RingBuffer{ring: r.ring, fields: merged, group: r.group, now: r.now}
+	if r.forward != nil {
+		child.forward = r.forward.WithFields(fields)
+	}
+	return child
+}
+
+func (r *RingBuffer) WithAttrs(fields ...Field) Logger {
+	return r.WithFields(NewFields(fields...))
+}
+
+// WithGroup returns a RingBuffer sharing the lines of r, recording the subsequent fields with a "name." prefix.
+func (r *RingBuffer) WithGroup(name string) Logger {
+	if name == "" {
+		return r
+	}
+	group := name
+	if r.group != "" {
+		group = r.group + "." + name
+	}
+	child := &RingBuffer{ring: r.ring, fields: r.fields, group: group, now: r.now}
+	if r.forward != nil {
+		child.forward = r.forward.WithGroup(name)
+	}
+	return child
+}
+
+func (r *RingBuffer) GetDelegate() interface{} {
+	if r.forward != nil {
+		return r.forward.GetDelegate()
+	}
+	return r
+}
+
+func (r *RingBuffer) Sync() error {
+	if r.forward != nil {
+		return r.forward.Sync()
+	}
+	return nil
+}
+
+func (r *RingBuffer) SyncContext(ctx context.Context) error {
+	return syncContext(ctx, r.Sync)
+}
+
+func (r *RingBuffer) Close() error {
+	if r.forward != nil {
+		return r.forward.Close()
+	}
+	return nil
+}
+
+func (r *RingBuffer) Rotate() error {
+	if r.forward != nil {
+		return r.forward.Rotate()
+	}
+	return nil
+}
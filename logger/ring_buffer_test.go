@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRingBufferKeepsLastLines(t *testing.T) {
+	rb := NewRingBuffer(3)
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	rb.now = func() time.Time { return at }
+	for i := 0; i < 5; i++ {
+		rb.Info("line " + strconv.Itoa(i))
+	}
+	rb.WithFields(Fields{"b": 2, "a": 1}).Warn("with fields")
+
+	want := []string{
+		"2024-03-01T10:00:00Z info line 3",
+		"2024-03-01T10:00:00Z info line 4",
+		"2024-03-01T10:00:00Z warn with fields a=1 b=2",
+	}
+	if got := rb.Dump(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dump = %q, want %q", got, want)
+	}
+}
+
+func TestRingBufferPartiallyFilled(t *testing.T) {
+	rb := NewRingBuffer(3)
+	if got := rb.Dump(); len(got) != 0 {
+		t.Fatalf("Dump of an empty ring = %q", got)
+	}
+	rb.Debug("only")
+	if got := rb.Dump(); len(got) != 1 {
+		t.Fatalf("Dump = %q, want one line", got)
+	}
+}
+
+func TestWrapRingBufferForwards(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{ConsoleLevel: warnLvl})
+			rb := WrapRingBuffer(l, 10)
+			rb.Info("recorded only")
+			rb.WithFields(Fields{"k": "v"}).Warn("forwarded")
+
+			if n := len(rb.Dump()); n != 2 {
+				t.Fatalf("recorded %d lines, want 2 whatever the forwarded level", n)
+			}
+			es := entries(t, buf)
+			if len(es) != 1 {
+				t.Fatalf("forwarded %d entries, want 1", len(es))
+			}
+			assertFields(t, es[0], map[string]interface{}{"msg": "forwarded", "k": "v"})
+		})
+	}
+}
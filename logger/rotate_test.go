@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// logFiles returns the content of every file of dir, by name.
+func logFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	names, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string, len(names))
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name.Name()] = string(content)
+	}
+	return files
+}
+
+// rotatedFileConfig logs to dir/app.log, archives are neither compressed nor removed in the background.
+func rotatedFileConfig(dir string) Configuration {
+	compress := false
+	return Configuration{EnableFile: true, FileLevel: infoLvl, FileLocation: filepath.Join(dir, "app.log"), FileCompress: &compress, FileMaxAgeDays: -1}
+}
+
+func TestRotateStartsNewFile(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			l, err := NewLogger(rotatedFileConfig(dir), backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.Info("before rotation")
+			if err := l.Rotate(); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+			l.Info("after rotation")
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			files := logFiles(t, dir)
+			if len(files) != 2 {
+				t.Fatalf("files %v, want the current file and one archive", files)
+			}
+			for name, content := range files {
+				want := "before rotation"
+				if name == "app.log" {
+					want = "after rotation"
+				}
+				if strings.Count(content, "\n") != 1 || !strings.Contains(content, want) {
+					t.Errorf("file %s = %q, want only %q", name, content, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRotateWithoutFile(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, _ := newBufferLogger(t, backend, Configuration{})
+			if err := l.Rotate(); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+		})
+	}
+}
+
+func TestRotateOnSIGHUP(t *testing.T) {
+	resetGlobal(t)
+	dir := t.TempDir()
+	if _, err := InitLogger(rotatedFileConfig(dir), LoggerBackendZap); err != nil {
+		t.Fatalf("InitLogger: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+	stop := RotateOnSIGHUP()
+	defer stop()
+
+	Info("before rotation")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(logFiles(t, dir)) != 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("files %v, want an archive after SIGHUP", logFiles(t, dir))
+		}
+	}
+}
@@ -0,0 +1,100 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogHandler adapts Logger into a slog.Handler.
+type slogHandler struct {
+	logger Logger
+	fields Fields
+}
+
+// AsSlogHandler returns a slog.Handler which writes records through l, so that libraries logging with slog
+// end up in the configured backend. Attributes are passed as fields, groups are nested with Logger.WithGroup.
+// Level filtering is left to the backend.
+func AsSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{logger: l, fields: Fields{}}
+}
+
+func (h *slogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(Fields, len(h.fields)+r.NumAttrs())
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, attr)
+		return true
+	})
+
+	l := h.logger
+	if len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+	switch {
+	case r.Level < slog.LevelInfo:
+		l.Debug(r.Message)
+	case r.Level < slog.LevelWarn:
+		l.Info(r.Message)
+	case r.Level < slog.LevelError:
+		l.Warn(r.Message)
+	default:
+		l.Error(r.Message)
+	}
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, attr := range attrs {
+		addSlogAttr(fields, attr)
+	}
+	return &slogHandler{logger: h.logger, fields: fields}
+}
+
+// WithGroup applies the pending fields outside of the group, then opens the group on the logger.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	l := h.logger
+	if len(h.fields) > 0 {
+		l = l.WithFields(h.fields)
+	}
+	return &slogHandler{logger: l.WithGroup(name), fields: Fields{}}
+}
+
+// addSlogAttr adds attr to fields, group attributes as nested maps, or inlined when their key is empty.
+func addSlogAttr(fields Fields, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		fields[attr.Key] = attr.Value.Any()
+		return
+	}
+	if attr.Key == "" {
+		for _, a := range attr.Value.Group() {
+			addSlogAttr(fields, a)
+		}
+		return
+	}
+	group := Fields{}
+	for _, a := range attr.Value.Group() {
+		addSlogAttr(group, a)
+	}
+	if len(group) > 0 {
+		fields[attr.Key] = map[string]interface{}(group)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestAsSlogHandler(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			s := slog.New(AsSlogHandler(l)).With("service", "api")
+			s.Warn("slow query", "table", "users", slog.Group("req", "id", 7))
+			s.WithGroup("db").Debug("connected", "pool", 4)
+
+			es := entries(t, buf)
+			if len(es) != 2 {
+				t.Fatalf("got %d entries, want 2", len(es))
+			}
+			assertFields(t, es[0], map[string]interface{}{"msg": "slow query", "service": "api", "table": "users"})
+			// zap writes "warn" and logrus "warning".
+			if level, _ := es[0]["level"].(string); !strings.HasPrefix(level, "warn") {
+				t.Errorf("level = %q, want warn", level)
+			}
+			if req, ok := es[0]["req"].(map[string]interface{}); !ok || req["id"] != float64(7) {
+				t.Errorf("group req = %v, want id 7", es[0]["req"])
+			}
+			assertFields(t, es[1], map[string]interface{}{"level": "debug", "msg": "connected", "service": "api"}, "pool")
+			if db, ok := es[1]["db"].(map[string]interface{}); !ok || db["pool"] != float64(4) {
+				t.Errorf("group db = %v, want pool 4", es[1]["db"])
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package logger_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/linhbkhn95/golang-british/logger"
+)
+
+// captureStack is a named frame expected in the stack.
+func captureStack() string {
+	return logger.Stack()
+}
+
+func TestStack(t *testing.T) {
+	stack := captureStack()
+	lines := strings.Split(stack, "\n")
+	if !strings.HasSuffix(lines[0], "logger_test.captureStack") {
+		t.Fatalf("first frame is %q, want captureStack in\n%s", lines[0], stack)
+	}
+	if !strings.Contains(stack, "logger_test.TestStack") {
+		t.Errorf("stack does not contain the test function:\n%s", stack)
+	}
+	if strings.Contains(stack, "golang-british/logger.") {
+		t.Errorf("stack contains frames of the logger package:\n%s", stack)
+	}
+	for i, line := range lines {
+		// Frames alternate the function and its indented location.
+		if (i%2 == 1) != strings.HasPrefix(line, "\t") {
+			t.Fatalf("line %d %q is not formatted as function then location:\n%s", i, line, stack)
+		}
+	}
+}
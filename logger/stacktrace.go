@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stacktraceKey is the field holding the stack trace, the same as zap's default.
+const stacktraceKey = "stacktrace"
+
+// stacktraceHook is a logrus hook which adds the stack trace of the caller to entries at or above level.
+type stacktraceHook struct {
+	level logrus.Level
+}
+
+func (h stacktraceHook) Levels() []logrus.Level {
+	levels := make([]logrus.Level, 0, len(logrus.AllLevels))
+	for _, l := range logrus.AllLevels {
+		// logrus levels are ordered from the most to the least severe.
+		if l <= h.level {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
+
+func (h stacktraceHook) Fire(entry *logrus.Entry) error {
+	entry.Data[stacktraceKey] = callerStack()
+	return nil
+}
+
+// Stack returns the stack of the calling goroutine, one "function\n\tfile:line" pair per frame,
+// without the frames of this package and of the logging backends. It suits a "stack" field, e.g. when recovering a panic.
+func Stack() string {
+	return callerStack()
+}
+
+// callerStack returns the stack of the goroutine, skipping the frames of logrus and of this package.
+func callerStack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !isLoggerFrame(frame.Function) {
+			fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func isLoggerFrame(function string) bool {
+	return strings.HasPrefix(function, "github.com/sirupsen/logrus") ||
+		strings.HasPrefix(function, "github.com/linhbkhn95/golang-british/logger.")
+}
@@ -0,0 +1,31 @@
+package logger
+
+import "testing"
+
+func TestStacktraceLevel(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{StacktraceLevel: errorLvl})
+			l.Info("info line")
+			l.Error("error line")
+			es := entries(t, buf)
+			if len(es) != 2 {
+				t.Fatalf("got %d entries, want 2", len(es))
+			}
+			assertFields(t, es[0], nil, stacktraceKey)
+			if stack, _ := es[1][stacktraceKey].(string); stack == "" {
+				t.Fatalf("error entry %v has no stack trace", es[1])
+			}
+		})
+	}
+}
+
+func TestStacktraceDisabledByDefault(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			l.Error("error line")
+			assertFields(t, entries(t, buf)[0], nil, stacktraceKey)
+		})
+	}
+}
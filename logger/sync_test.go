@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncContextDeadline(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			// The async writer is blocked writing the entry, so Sync waits for w.
+			w := &blockingWriter{release: make(chan struct{})}
+			defer close(w.release)
+			l, err := NewLogger(Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: w, Async: true}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			l.Info("line")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			if err := l.SyncContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("SyncContext = %v, want context.DeadlineExceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("SyncContext returned after %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestSyncContextCompletes(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, err := NewLogger(Configuration{EnableConsole: true, ConsoleLevel: infoLvl, ConsoleWriter: &lockedBuffer{}}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			if err := l.SyncContext(context.Background()); err != nil {
+				t.Fatalf("SyncContext: %v", err)
+			}
+		})
+	}
+}
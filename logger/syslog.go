@@ -0,0 +1,25 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+
+	"github.com/sirupsen/logrus"
+	lsyslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// newSyslogWriter dials the syslog daemon configured by config. An empty SyslogNetwork connects to the local daemon.
+func newSyslogWriter(config Configuration) (syslogSender, io.Closer, error) {
+	w, err := syslog.Dial(config.SyslogNetwork, config.SyslogAddr, syslog.LOG_INFO|syslog.LOG_USER, config.SyslogTag)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, w, nil
+}
+
+// newSyslogHook returns a logrus hook forwarding entries to the syslog daemon configured by config.
+func newSyslogHook(config Configuration) (logrus.Hook, error) {
+	return lsyslog.NewSyslogHook(config.SyslogNetwork, config.SyslogAddr, syslog.LOG_INFO|syslog.LOG_USER, config.SyslogTag)
+}
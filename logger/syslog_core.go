@@ -0,0 +1,96 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+// syslogSender is the part of *syslog.Writer sending a message at a given severity.
+type syslogSender interface {
+	Debug(m string) error
+	Info(m string) error
+	Warning(m string) error
+	Err(m string) error
+	Crit(m string) error
+}
+
+// syslogCore writes entries with the syslog severity of their level, a zapcore.ioCore writing to a *syslog.Writer
+// would send all of them at the priority it was dialed with. The level travels as the first byte of every write,
+// through the async writer if any, down to the syslogLevelWriter which strips it.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out zapcore.WriteSyncer
+}
+
+func newSyslogCore(enc zapcore.Encoder, out zapcore.WriteSyncer, enab zapcore.LevelEnabler) *syslogCore {
+	return &syslogCore{LevelEnabler: enab, enc: enc, out: out}
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &syslogCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), out: c.out}
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	p := make([]byte, 0, buf.Len()+1)
+	p = append(append(p, levelMarker(ent.Level)), buf.Bytes()...)
+	if _, err := c.out.Write(p); err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// Like zapcore.ioCore, sync before a panic or an exit.
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *syslogCore) Sync() error {
+	return c.out.Sync()
+}
+
+// levelMarker returns the first byte of the writes of lvl, a control character which never starts a text line.
+func levelMarker(lvl zapcore.Level) byte {
+	return byte(lvl-zapcore.DebugLevel) + 1
+}
+
+// syslogLevelWriter sends every write at the severity of the level marked by its first byte, see syslogCore.
+// Writes without marker are sent as warnings.
+type syslogLevelWriter struct {
+	s syslogSender
+}
+
+func (w syslogLevelWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	send, msg := w.s.Warning, p
+	switch p[0] {
+	case levelMarker(zapcore.DebugLevel):
+		send, msg = w.s.Debug, p[1:]
+	case levelMarker(zapcore.InfoLevel):
+		send, msg = w.s.Info, p[1:]
+	case levelMarker(zapcore.WarnLevel):
+		send, msg = w.s.Warning, p[1:]
+	case levelMarker(zapcore.ErrorLevel):
+		send, msg = w.s.Err, p[1:]
+	case levelMarker(zapcore.DPanicLevel), levelMarker(zapcore.PanicLevel), levelMarker(zapcore.FatalLevel):
+		send, msg = w.s.Crit, p[1:]
+	}
+	if err := send(string(msg)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type sentMessage struct {
+	severity string
+	msg      string
+}
+
+// fakeSyslog records the messages sent with their severity.
+type fakeSyslog struct {
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+func (f *fakeSyslog) send(severity, m string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, sentMessage{severity: severity, msg: m})
+	return nil
+}
+
+func (f *fakeSyslog) Debug(m string) error   { return f.send("debug", m) }
+func (f *fakeSyslog) Info(m string) error    { return f.send("info", m) }
+func (f *fakeSyslog) Warning(m string) error { return f.send("warning", m) }
+func (f *fakeSyslog) Err(m string) error     { return f.send("err", m) }
+func (f *fakeSyslog) Crit(m string) error    { return f.send("crit", m) }
+
+func (f *fakeSyslog) messages() []sentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]sentMessage(nil), f.sent...)
+}
+
+func TestSyslogCoreSeverities(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		fake := &fakeSyslog{}
+		var writer zapcore.WriteSyncer = zapcore.AddSync(syslogLevelWriter{s: fake})
+		if async {
+			writer = newAsyncWriter(writer, 0)
+		}
+		core := newSyslogCore(getEncoder(false, FieldKeys{}, false), writer, zapcore.DebugLevel)
+		l := zap.New(core).With(zap.String("k", "v"))
+		l.Debug("debug")
+		l.Info("info")
+		l.Warn("warn")
+		l.Error("error")
+		if err := l.Sync(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"debug", "info", "warning", "err"}
+		sent := fake.messages()
+		if len(sent) != len(want) {
+			t.Fatalf("async=%v: sent %v, want %d messages", async, sent, len(want))
+		}
+		for i, m := range sent {
+			if m.severity != want[i] {
+				t.Errorf("async=%v: message %q sent at %s, want %s", async, m.msg, m.severity, want[i])
+			}
+			if m.msg == "" || m.msg[0] < ' ' || !strings.Contains(m.msg, `"k": "v"`) {
+				t.Errorf("async=%v: message %q is not the encoded entry", async, m.msg)
+			}
+		}
+	}
+}
+
+func TestSyslogCorePanicIsCritical(t *testing.T) {
+	fake := &fakeSyslog{}
+	core := newSyslogCore(getEncoder(false, FieldKeys{}, false), zapcore.AddSync(syslogLevelWriter{s: fake}), zapcore.InfoLevel)
+	func() {
+		defer func() { _ = recover() }()
+		zap.New(core).Panic("boom")
+	}()
+	if sent := fake.messages(); len(sent) != 1 || sent[0].severity != "crit" {
+		t.Fatalf("sent %v, want one crit message", sent)
+	}
+}
+
+func TestSyslogLevelWriterWithoutMarker(t *testing.T) {
+	fake := &fakeSyslog{}
+	if _, err := (syslogLevelWriter{s: fake}).Write([]byte("logger: dropped 3 entries\n")); err != nil {
+		t.Fatal(err)
+	}
+	if sent := fake.messages(); len(sent) != 1 || sent[0].severity != "warning" || sent[0].msg != "logger: dropped 3 entries\n" {
+		t.Fatalf("sent %v, want the message as a warning", sent)
+	}
+}
@@ -0,0 +1,105 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenSyslog returns a local UDP syslog listener.
+func listenSyslog(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readSyslog returns the next message received by conn.
+func readSyslog(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64<<10)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read syslog message: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogUDP(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			conn := listenSyslog(t)
+			l, err := NewLogger(Configuration{
+				EnableConsole: true,
+				ConsoleLevel:  infoLvl,
+				ConsoleWriter: io.Discard,
+				EnableSyslog:  true,
+				SyslogNetwork: "udp",
+				SyslogAddr:    conn.LocalAddr().String(),
+				SyslogTag:     "test-app",
+			}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			defer l.Close()
+
+			l.WithFields(Fields{"k": "v"}).Info("info message")
+			l.Error("error message")
+			// The priority is the user facility, 8, plus the severity of the level: info is 6 and err is 3.
+			for _, want := range []struct{ prefix, msg string }{{"<14>", "info message"}, {"<11>", "error message"}} {
+				got := readSyslog(t, conn)
+				if !strings.HasPrefix(got, want.prefix) || !strings.Contains(got, " test-app[") || !strings.Contains(got, want.msg) {
+					t.Fatalf("syslog message %q, want priority %s, tag test-app and %q", got, want.prefix, want.msg)
+				}
+				if want.msg == "info message" && !strings.Contains(got, "k=v") && !strings.Contains(got, `"k": "v"`) {
+					t.Errorf("syslog message %q does not contain the field k", got)
+				}
+			}
+		})
+	}
+}
+
+func TestSyslogUnreachableFallsBackToConsole(t *testing.T) {
+	// Nothing listens on a closed TCP port, so dialing it fails right away.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := lis.Addr().String()
+	_ = lis.Close()
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			out := &lockedBuffer{}
+			l, err := NewLogger(Configuration{
+				EnableConsole: true,
+				ConsoleLevel:  infoLvl,
+				ConsoleWriter: out,
+				EnableSyslog:  true,
+				SyslogNetwork: "tcp",
+				SyslogAddr:    addr,
+			}, backend)
+			if err != nil {
+				t.Fatalf("NewLogger: %v", err)
+			}
+			defer l.Close()
+
+			l.Info("after fallback")
+			got := string(out.bytes())
+			if !strings.Contains(got, "syslog is unavailable, falling back to console") {
+				t.Errorf("console output %q does not warn about syslog", got)
+			}
+			if !strings.Contains(got, "after fallback") {
+				t.Errorf("console output %q does not contain the entry", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+var errSyslogUnsupported = errors.New("syslog is not supported on this platform")
+
+func newSyslogWriter(Configuration) (syslogSender, io.Closer, error) {
+	return nil, nil, errSyslogUnsupported
+}
+
+func newSyslogHook(Configuration) (logrus.Hook, error) {
+	return nil, errSyslogUnsupported
+}
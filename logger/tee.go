@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// tee fans every call out to its members, in order.
+type tee []Logger
+
+// Tee returns a Logger writing every entry to each of loggers, e.g. to the console and to an audit sink.
+//
+// Fatal is forwarded in order until a member exits the process, so the members that must see fatal entries
+// should come first. Panic is forwarded to every member before panicking with the value of the first one.
+func Tee(loggers ...Logger) Logger {
+	return tee(append([]Logger(nil), loggers...))
+}
+
+func (t tee) Debugf(format string, args ...interface{}) {
+	t.Debug(fmt.Sprintf(format, args...))
+}
+
+func (t tee) Debug(msg string) {
+	for _, l := range t {
+		l.Debug(msg)
+	}
+}
+
+func (t tee) Infof(format string, args ...interface{}) {
+	t.Info(fmt.Sprintf(format, args...))
+}
+
+func (t tee) Info(msg string) {
+	for _, l := range t {
+		l.Info(msg)
+	}
+}
+
+func (t tee) Warnf(format string, args ...interface{}) {
+	t.Warn(fmt.Sprintf(format, args...))
+}
+
+func (t tee) Warn(msg string) {
+	for _, l := range t {
+		l.Warn(msg)
+	}
+}
+
+func (t tee) Errorf(format string, args ...interface{}) {
+	t.Error(fmt.Sprintf(format, args...))
+}
+
+func (t tee) Error(msg string) {
+	for _, l := range t {
+		l.Error(msg)
+	}
+}
+
+func (t tee) Fatalf(format string, args ...interface{}) {
+	t.Fatal(fmt.Sprintf(format, args...))
+}
+
+func (t tee) Fatal(msg string) {
+	for _, l := range t {
+		l.Fatal(msg)
+	}
+}
+
+func (t tee) Panicf(format string, args ...interface{}) {
+	t.Panic(fmt.Sprintf(format, args...))
+}
+
+func (t tee) Panic(msg string) {
+	var first interface{}
+	for _, l := range t {
+		if r := panicValue(func() { l.Panic(msg) }); r != nil && first == nil {
+			first = r
+		}
+	}
+	if first == nil {
+		first = msg
+	}
+	panic(first)
+}
+
+// panicValue calls fn and returns the value it panicked with, nil if it returned normally.
+func panicValue(fn func()) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	fn()
+	return nil
+}
+
+// WithFields returns a tee of the loggers derived from each member.
+func (t tee) WithFields(fields Fields) Logger {
+	derived := make(tee, len(t))
+	for i, l := range t {
+		derived[i] = l.WithFields(fields)
+	}
+	return derived
+}
+
+func (t tee) WithAttrs(fields ...Field) Logger {
+	return t.WithFields(NewFields(fields...))
+}
+
+// WithGroup returns a tee of the loggers derived from each member.
+func (t tee) WithGroup(name string) Logger {
+	derived := make(tee, len(t))
+	for i, l := range t {
+		derived[i] = l.WithGroup(name)
+	}
+	return derived
+}
+
+// GetDelegate returns the delegates of the members, as a []interface{}.
+func (t tee) GetDelegate() interface{} {
+	delegates := make([]interface{}, len(t))
+	for i, l := range t {
+		delegates[i] = l.GetDelegate()
+	}
+	return delegates
+}
+
+// Sync syncs every member, joining their errors.
+func (t tee) Sync() error {
+	var err error
+	for _, l := range t {
+		err = multierr.Append(err, l.Sync())
+	}
+	return err
+}
+
+func (t tee) SyncContext(ctx context.Context) error {
+	return syncContext(ctx, t.Sync)
+}
+
+// Close closes every member, joining their errors.
+func (t tee) Close() error {
+	var err error
+	for _, l := range t {
+		err = multierr.Append(err, l.Close())
+	}
+	return err
+}
+
+// Rotate rotates the files of every member, joining their errors.
+func (t tee) Rotate() error {
+	var err error
+	for _, l := range t {
+		err = multierr.Append(err, l.Rotate())
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// failingSync is a Logger whose Sync fails with err.
+type failingSync struct {
+	Logger
+	err error
+}
+
+func (l failingSync) Sync() error {
+	return l.err
+}
+
+func TestTeeFansOut(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC) })
+	t.Cleanup(func() { SetClock(nil) })
+	first, second := NewJSONCapture(), NewJSONCapture()
+	l := Tee(first, second).WithFields(Fields{"request_id": "req-1"}).WithGroup("user").WithAttrs(Int("id", 7))
+
+	l.Info("info line")
+	l.Warnf("warn %d", 2)
+	l.Debug("debug line")
+
+	want := first.Entries()
+	if len(want) != 3 {
+		t.Fatalf("first logger got %d entries, want 3", len(want))
+	}
+	if got := second.Entries(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("second logger entries %v, want %v", got, want)
+	}
+	assertFields(t, want[0], map[string]interface{}{"msg": "info line", "request_id": "req-1"})
+	if group, _ := want[0]["user"].(map[string]interface{}); group["id"] != float64(7) {
+		t.Fatalf("group user = %v, want id=7", want[0]["user"])
+	}
+}
+
+func TestTeeSyncJoinsErrors(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	l := Tee(failingSync{NewJSONCapture(), errFirst}, NewJSONCapture(), failingSync{NewJSONCapture(), errSecond})
+	err := l.Sync()
+	if errs := multierr.Errors(err); len(errs) != 2 || errs[0] != errFirst || errs[1] != errSecond {
+		t.Fatalf("Sync error = %v, want first and second", err)
+	}
+}
@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationAndTimeFields(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 20, 30, 500*int(time.Millisecond), time.UTC)
+	wantTime := map[string]string{
+		"zap":    "2024-03-01T10:20:30.500Z",
+		"logrus": "2024-03-01T10:20:30.5Z",
+	}
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			want := map[string]interface{}{"timeout": "1.5s", "at": wantTime[name]}
+			assertFields(t, lastEntry(t, l.WithAttrs(Duration("timeout", 1500*time.Millisecond), Time("at", at)), buf), want)
+			assertFields(t, lastEntry(t, l.WithFields(Fields{"timeout": 1500 * time.Millisecond, "at": at}), buf), want)
+		})
+	}
+}
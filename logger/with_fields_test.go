@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var backends = map[string]LoggerBackend{
+	"zap":    LoggerBackendZap,
+	"logrus": LoggerBackendLogrus,
+}
+
+// newBufferLogger returns a logger of backend writing JSON lines at debug level to the returned buffer.
+func newBufferLogger(t *testing.T, backend LoggerBackend, cfg Configuration) (Logger, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	cfg.EnableConsole = true
+	cfg.ConsoleJSONFormat = true
+	if cfg.ConsoleLevel == "" && cfg.ConsoleLevelTyped == "" {
+		cfg.ConsoleLevel = debugLvl
+	}
+	cfg.ConsoleWriter = buf
+	l, err := NewLogger(cfg, backend)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	return l, buf
+}
+
+// entries decodes the JSON lines of buf and resets it.
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var res []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		res = append(res, entry)
+	}
+	buf.Reset()
+	return res
+}
+
+// lastEntry logs msg through l at info level and returns the decoded entry.
+func lastEntry(t *testing.T, l Logger, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	l.Info("msg")
+	es := entries(t, buf)
+	if len(es) != 1 {
+		t.Fatalf("got %d entries, want 1", len(es))
+	}
+	return es[0]
+}
+
+func assertFields(t *testing.T, entry map[string]interface{}, want map[string]interface{}, absent ...string) {
+	t.Helper()
+	for k, v := range want {
+		if got, ok := entry[k]; !ok || got != v {
+			t.Errorf("field %q = %v, want %v (entry %v)", k, got, v, entry)
+		}
+	}
+	for _, k := range absent {
+		if got, ok := entry[k]; ok {
+			t.Errorf("field %q = %v, want absent (entry %v)", k, got, entry)
+		}
+	}
+}
+
+func TestWithFieldsOverrideAndIsolation(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			base := l.WithFields(Fields{"a": "base", "b": "base"})
+			first := base.WithFields(Fields{"b": "first", "c": "first"})
+			second := base.WithFields(Fields{"c": "second"})
+
+			assertFields(t, lastEntry(t, base, buf), map[string]interface{}{"a": "base", "b": "base"}, "c")
+			assertFields(t, lastEntry(t, first, buf), map[string]interface{}{"a": "base", "b": "first", "c": "first"})
+			assertFields(t, lastEntry(t, second, buf), map[string]interface{}{"a": "base", "b": "base", "c": "second"})
+		})
+	}
+}
+
+func TestWithFieldsCopiesTheMap(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			fields := Fields{"a": "before"}
+			child := l.WithFields(fields)
+			fields["a"] = "after"
+			fields["b"] = "added"
+
+			assertFields(t, lastEntry(t, child, buf), map[string]interface{}{"a": "before"}, "b")
+		})
+	}
+}
+
+func TestWithFieldsOverriddenKeyWrittenOnce(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			l.WithFields(Fields{"k": 1}).WithFields(Fields{"x": 1}).WithFields(Fields{"k": 2}).Info("msg")
+			if n := strings.Count(buf.String(), `"k":`); n != 1 {
+				t.Fatalf("key written %d times in %s", n, buf.String())
+			}
+			assertFields(t, entries(t, buf)[0], map[string]interface{}{"k": float64(2), "x": float64(1)})
+		})
+	}
+}
+
+func TestWithFieldsOverrideInGroup(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			child := l.WithFields(Fields{"k": "outer"}).WithGroup("g").WithFields(Fields{"k": "inner", "a": 1}).
+				WithFields(Fields{"a": 2})
+			entry := lastEntry(t, child, buf)
+			assertFields(t, entry, map[string]interface{}{"k": "outer"})
+			group, ok := entry["g"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("group g missing in %v", entry)
+			}
+			assertFields(t, group, map[string]interface{}{"k": "inner", "a": float64(2)})
+		})
+	}
+}
+
+func TestWithGroupNests(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			db := l.WithFields(Fields{"request_id": "req-1"}).WithGroup("db").WithFields(Fields{"query": "SELECT 1", "rows": 5})
+			entry := lastEntry(t, db.WithGroup("").WithGroup("conn").WithAttrs(String("host", "primary")), buf)
+
+			assertFields(t, entry, map[string]interface{}{"request_id": "req-1"}, "query", "rows", "conn", "host")
+			group, ok := entry["db"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("group db missing in %v", entry)
+			}
+			assertFields(t, group, map[string]interface{}{"query": "SELECT 1", "rows": float64(5)}, "request_id", "host")
+			conn, ok := group["conn"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("group db.conn missing in %v", entry)
+			}
+			assertFields(t, conn, map[string]interface{}{"host": "primary"})
+
+			// The parent logger is left without the groups.
+			assertFields(t, lastEntry(t, l, buf), nil, "db")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	stdlog "log"
+	"strings"
+)
+
+// levelWriter is an io.Writer logging every Write as one entry at a fixed level.
+type levelWriter struct {
+	write func(msg string)
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	w.write(string(bytes.TrimRight(p, "\r\n")))
+	return len(p), nil
+}
+
+// WriterAt returns an io.Writer which logs every Write through l as one entry at level, trailing newline trimmed.
+// Unknown levels fall back to info.
+func WriterAt(l Logger, level string) io.Writer {
+	switch level {
+	case debugLvl:
+		return levelWriter{write: l.Debug}
+	case warnLvl:
+		return levelWriter{write: l.Warn}
+	case errorLvl:
+		return levelWriter{write: l.Error}
+	case fatalLvl:
+		return levelWriter{write: l.Fatal}
+	case panicLvl:
+		return levelWriter{write: l.Panic}
+	default:
+		return levelWriter{write: l.Info}
+	}
+}
+
+// StdLogAt returns a standard library logger writing through l at level, e.g. for http.Server.ErrorLog.
+func StdLogAt(l Logger, level string) *stdlog.Logger {
+	return stdlog.New(WriterAt(l, level), "", 0)
+}
+
+// PrintLogger is the Printf style logger expected by ORMs and database/sql drivers.
+type PrintLogger interface {
+	Printf(format string, args ...interface{})
+	Print(args ...interface{})
+}
+
+type sqlLogger struct {
+	l Logger
+}
+
+// SQLLogger returns a PrintLogger logging every call through l as one debug entry, trailing newline trimmed.
+func SQLLogger(l Logger) PrintLogger {
+	return sqlLogger{l: l}
+}
+
+func (s sqlLogger) Printf(format string, args ...interface{}) {
+	s.l.Debug(strings.TrimRight(fmt.Sprintf(format, args...), "\r\n"))
+}
+
+func (s sqlLogger) Print(args ...interface{}) {
+	s.l.Debug(strings.TrimRight(fmt.Sprint(args...), "\r\n"))
+}
@@ -0,0 +1,51 @@
+package logger
+
+import "testing"
+
+func TestStdLogAt(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			StdLogAt(l, errorLvl).Print("disk full")
+			es := entries(t, buf)
+			if len(es) != 1 {
+				t.Fatalf("got %d entries, want 1", len(es))
+			}
+			assertFields(t, es[0], map[string]interface{}{"level": "error", "msg": "disk full"})
+		})
+	}
+}
+
+func TestWriterAtUnknownLevel(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			if _, err := WriterAt(l, "verbose").Write([]byte("line\r\n")); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			assertFields(t, entries(t, buf)[0], map[string]interface{}{"level": "info", "msg": "line"})
+		})
+	}
+}
+
+func TestSQLLogger(t *testing.T) {
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			l, buf := newBufferLogger(t, backend, Configuration{})
+			sql := SQLLogger(l)
+			sql.Printf("query %s", "SELECT 1")
+			es := entries(t, buf)
+			if len(es) != 1 {
+				t.Fatalf("got %d entries, want 1", len(es))
+			}
+			assertFields(t, es[0], map[string]interface{}{"level": "debug", "msg": "query SELECT 1"})
+
+			sql.Print("query ", "SELECT 2\n")
+			es = entries(t, buf)
+			if len(es) != 1 {
+				t.Fatalf("got %d entries, want 1", len(es))
+			}
+			assertFields(t, es[0], map[string]interface{}{"level": "debug", "msg": "query SELECT 2"})
+		})
+	}
+}
@@ -1,27 +1,76 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"io"
+	"math"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 )
 
 type zapLogger struct {
+	// sugaredLogger carries the fields, without the LazyValue ones which are only added for enabled entries.
 	sugaredLogger *zap.SugaredLogger
+	// logger is sugaredLogger, desugared so that new fields are passed as a []zap.Field, without boxing the keys.
+	logger *zap.Logger
+	// base is the logger without fields, they are re-applied on it when a key is overridden.
+	base *zap.Logger
+	// fields are the fields of every WithFields call, the last one first, see fieldNode.
+	fields  *fieldNode
+	groups  []string
+	hasLazy bool
+	// humanizeBytes renders ByteSize fields as strings, see Configuration.HumanizeBytes.
+	humanizeBytes bool
+	// closers are the files opened by the logger, shared with the loggers built with WithFields.
+	closers []io.Closer
+	// core is the core of base, used to check levels without desugaring the logger.
+	core zapcore.Core
 }
 
-func getEncoder(isJSON bool) zapcore.Encoder {
+// fieldNode holds the fields of one WithFields call, sorted by key, and links to the ones of the previous calls.
+// Nodes are shared by the derived loggers and never modified.
+type fieldNode struct {
+	fields []Field
+	// group is the number of groups the fields are nested in.
+	group int
+	prev  *fieldNode
+}
+
+func getEncoder(isJSON bool, keys FieldKeys, color bool) zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+	applyZapFieldKeys(&encoderConfig, keys)
+	if color {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
 	if isJSON {
 		return zapcore.NewJSONEncoder(encoderConfig)
 	}
 	return zapcore.NewConsoleEncoder(encoderConfig)
 }
 
+func applyZapFieldKeys(encoderConfig *zapcore.EncoderConfig, keys FieldKeys) {
+	if keys.TimeKey != "" {
+		encoderConfig.TimeKey = keys.TimeKey
+	}
+	if keys.LevelKey != "" {
+		encoderConfig.LevelKey = keys.LevelKey
+	}
+	if keys.MessageKey != "" {
+		encoderConfig.MessageKey = keys.MessageKey
+	}
+	if keys.NameKey != "" {
+		encoderConfig.NameKey = keys.NameKey
+	}
+	if keys.CallerKey != "" {
+		encoderConfig.CallerKey = keys.CallerKey
+	}
+}
+
 func getZapLevel(level string) zapcore.Level {
 	switch level {
 	case infoLvl:
@@ -34,107 +83,361 @@ func getZapLevel(level string) zapcore.Level {
 		return zapcore.ErrorLevel
 	case fatalLvl:
 		return zapcore.FatalLevel
+	case panicLvl:
+		return zapcore.PanicLevel
 	default:
 		return zapcore.InfoLevel
 	}
 }
 
+func newZapConsoleCore(config Configuration, async func(zapcore.WriteSyncer) zapcore.WriteSyncer) zapcore.Core {
+	level := getZapLevel(config.ConsoleLevel)
+	writer := async(zapcore.Lock(zapcore.AddSync(config.consoleWriter())))
+	return zapcore.NewCore(getEncoder(config.ConsoleJSONFormat, config.FieldKeys, config.consoleColored()), writer, level)
+}
+
+// newZapLogger builds one core per enabled writer, each with its own level filter, and tees them together.
 func newZapLogger(config Configuration) (Logger, error) {
+	fileErr := config.checkFileWritable()
+	if fileErr != nil {
+		if config.StrictFile {
+			return nil, fileErr
+		}
+		config.EnableFile = false
+		config.EnableConsole = true
+	}
+
 	cores := []zapcore.Core{}
+	closers := []io.Closer{}
+	// The async writers are closed before the files they write to.
+	var asyncClosers []io.Closer
+	async := func(writer zapcore.WriteSyncer) zapcore.WriteSyncer {
+		if !config.Async {
+			return writer
+		}
+		w := newAsyncWriter(writer, config.AsyncBufferSize)
+		asyncClosers = append(asyncClosers, w)
+		return w
+	}
 
 	if config.EnableConsole {
-		level := getZapLevel(config.ConsoleLevel)
-		writer := zapcore.Lock(os.Stdout)
-		core := zapcore.NewCore(getEncoder(config.ConsoleJSONFormat), writer, level)
-		cores = append(cores, core)
+		cores = append(cores, newZapConsoleCore(config, async))
 	}
 
 	if config.EnableFile {
 		level := getZapLevel(config.FileLevel)
-		writer := zapcore.AddSync(&lumberjack.Logger{
-			Filename: config.FileLocation,
-			MaxSize:  100,
-			Compress: true,
-			MaxAge:   28,
-		})
-		core := zapcore.NewCore(getEncoder(config.FileJSONFormat), writer, level)
+		fileOut, fileClosers := config.fileOutput()
+		closers = append(closers, fileClosers...)
+		writer := zapcore.AddSync(fileOut)
+		if config.FileBufferSize > 0 {
+			writer = newBufferedWriter(fileOut, config.FileBufferSize)
+		}
+		core := zapcore.NewCore(getEncoder(config.FileJSONFormat, config.FieldKeys, false), async(writer), level)
 		cores = append(cores, core)
 	}
 
+	var syslogErr error
+	if config.EnableSyslog {
+		sender, closer, err := newSyslogWriter(config)
+		if err != nil {
+			syslogErr = err
+			if !config.EnableConsole {
+				cores = append(cores, newZapConsoleCore(config, async))
+			}
+		} else {
+			closers = append(closers, closer)
+			level := getZapLevel(config.ConsoleLevel)
+			writer := async(zapcore.AddSync(syslogLevelWriter{s: sender}))
+			cores = append(cores, newSyslogCore(getEncoder(false, config.FieldKeys, false), writer, level))
+		}
+	}
+
+	closers = append(asyncClosers, closers...)
 	combinedCore := zapcore.NewTee(cores...)
 
 	// AddCallerSkip skips 2 number of callers, this is important else the file that gets
 	// logged will always be the wrapped file. In our case zap.go
-	logger := zap.New(combinedCore,
+	opts := []zap.Option{
 		zap.AddCallerSkip(2),
 		zap.AddCaller(),
-	).Sugar()
+		zap.WithClock(zapClock{}),
+	}
+	if config.StacktraceLevel != "" {
+		opts = append(opts, zap.AddStacktrace(getZapLevel(config.StacktraceLevel)))
+	}
+	// Cores sync themselves after writing a fatal entry, so nothing is lost on exit.
+	if config.FatalPanics {
+		opts = append(opts, zap.WithFatalHook(zapcore.WriteThenPanic))
+	} else {
+		opts = append(opts, zap.WithFatalHook(zapExitHook{}))
+	}
+	logger := zap.New(combinedCore, opts...).Sugar()
+	if fileErr != nil {
+		logger.Warnf("log file is unwritable, falling back to console: %v", fileErr)
+	}
+	if syslogErr != nil {
+		logger.Warnf("syslog is unavailable, falling back to console: %v", syslogErr)
+	}
 
 	return &zapLogger{
 		sugaredLogger: logger,
+		logger:        logger.Desugar(),
+		base:          logger.Desugar(),
+		closers:       closers,
+		core:          combinedCore,
+		humanizeBytes: config.HumanizeBytes,
 	}, nil
 }
 
+// zapExitHook exits through exitFunc so that it can be swapped in tests.
+type zapExitHook struct{}
+
+func (zapExitHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	exitFunc(1)
+}
+
 func (l *zapLogger) Debugf(format string, args ...interface{}) {
-	l.sugaredLogger.Debugf(format, args...)
+	l.sugared(zapcore.DebugLevel).Debugf(format, args...)
 }
 
+// Debug goes through the desugared logger, so that msg is not boxed into the variadic arguments of zap.
 func (l *zapLogger) Debug(msg string) {
-	l.sugaredLogger.Debug(msg)
+	l.desugared(zapcore.DebugLevel).Debug(msg)
 }
 
 func (l *zapLogger) Infof(format string, args ...interface{}) {
-	l.sugaredLogger.Infof(format, args...)
+	l.sugared(zapcore.InfoLevel).Infof(format, args...)
 }
 
 func (l *zapLogger) Info(msg string) {
-	l.sugaredLogger.Info(msg)
+	l.desugared(zapcore.InfoLevel).Info(msg)
 }
 
 func (l *zapLogger) Warnf(format string, args ...interface{}) {
-	l.sugaredLogger.Warnf(format, args...)
+	l.sugared(zapcore.WarnLevel).Warnf(format, args...)
 }
 
 func (l *zapLogger) Warn(msg string) {
-	l.sugaredLogger.Warn(msg)
+	l.desugared(zapcore.WarnLevel).Warn(msg)
 }
 
 func (l *zapLogger) Errorf(format string, args ...interface{}) {
-	l.sugaredLogger.Errorf(format, args...)
+	l.sugared(zapcore.ErrorLevel).Errorf(format, args...)
 }
 
 func (l *zapLogger) Error(msg string) {
-	l.sugaredLogger.Error(msg)
+	l.desugared(zapcore.ErrorLevel).Error(msg)
 }
 
 func (l *zapLogger) Fatalf(format string, args ...interface{}) {
-	l.sugaredLogger.Fatalf(format, args...)
+	l.sugared(zapcore.FatalLevel).Fatalf(format, args...)
 }
 
 func (l *zapLogger) Fatal(msg string) {
-	l.sugaredLogger.Fatal(msg)
+	l.sugared(zapcore.FatalLevel).Fatal(msg)
 }
 
 func (l *zapLogger) Panicf(format string, args ...interface{}) {
-	l.sugaredLogger.Panicf(format, args...)
+	l.sugared(zapcore.PanicLevel).Panicf(format, args...)
 }
 
 func (l *zapLogger) Panic(msg string) {
-	l.sugaredLogger.Panic(msg)
+	l.sugared(zapcore.PanicLevel).Panic(msg)
 }
 
 func (l *zapLogger) Sync() error {
 	return l.sugaredLogger.Sync()
 }
 
+func (l *zapLogger) Close() error {
+	return closeAll(l.Sync, l.closers)
+}
+
+func (l *zapLogger) Rotate() error {
+	return rotateAll(l.Sync, l.closers)
+}
+
+func (l *zapLogger) SyncContext(ctx context.Context) error {
+	return syncContext(ctx, l.Sync)
+}
+
 func (l *zapLogger) WithFields(fields Fields) Logger {
-	var fds = make([]interface{}, 0)
+	sorted := make([]Field, 0, len(fields))
 	for k, v := range fields {
-		fds = append(fds, k)
-		fds = append(fds, v)
+		sorted = append(sorted, Any(k, v))
 	}
-	newLogger := l.sugaredLogger.With(fds...)
-	return &zapLogger{newLogger}
+	sortFields(sorted)
+	return l.with(sorted)
+}
+
+// sortFields sorts fields by key. It is an insertion sort, fields of one call are few and sort.Slice allocates.
+func sortFields(fields []Field) {
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j].Key < fields[j-1].Key; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+}
+
+// with returns a child logger carrying fields, which must not be modified afterwards. The fields are added to
+// the zap logger of l, unless one of them overrides a key of the current group: the fields are then re-applied
+// on base, so that zap does not write the key twice.
+func (l *zapLogger) with(fields []Field) *zapLogger {
+	child := &zapLogger{
+		base:          l.base,
+		fields:        &fieldNode{fields: fields, group: len(l.groups), prev: l.fields},
+		groups:        l.groups,
+		hasLazy:       l.hasLazy,
+		humanizeBytes: l.humanizeBytes,
+		closers:       l.closers,
+		core:          l.core,
+	}
+	for _, f := range fields {
+		if _, ok := f.iface.(LazyValue); ok {
+			child.hasLazy = true
+		}
+	}
+	if l.overrides(fields) {
+		child.logger = l.base.With(child.zapFields(false)...)
+	} else {
+		child.logger = l.logger.With(child.appendZapFields(make([]zap.Field, 0, len(fields)), child.fields, nil, false)...)
+	}
+	child.sugaredLogger = child.logger.Sugar()
+	return child
+}
+
+// overrides reports whether a key of fields is already set in the current group.
+func (l *zapLogger) overrides(fields []Field) bool {
+	for n := l.fields; n != nil && n.group == len(l.groups); n = n.prev {
+		for _, f := range fields {
+			if n.has(f.Key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (n *fieldNode) has(key string) bool {
+	return hasField(n.fields, key)
+}
+
+// WithGroup returns a logger whose subsequent fields are nested under name, using zap namespaces.
+func (l *zapLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	child := &zapLogger{
+		logger:        l.logger.With(zap.Namespace(name)),
+		base:          l.base,
+		fields:        l.fields,
+		groups:        append(append(make([]string, 0, len(l.groups)+1), l.groups...), name),
+		hasLazy:       l.hasLazy,
+		humanizeBytes: l.humanizeBytes,
+		closers:       l.closers,
+		core:          l.core,
+	}
+	child.sugaredLogger = child.logger.Sugar()
+	return child
+}
+
+// zapFields returns the fields of every WithFields call in order, each group opening a namespace.
+// A key overridden by a later call of the same group is only returned once, with the later value at its position.
+// LazyValue fields are evaluated when eval is set, skipped otherwise.
+func (l *zapLogger) zapFields(eval bool) []zap.Field {
+	var nodes []*fieldNode
+	size := len(l.groups)
+	for n := l.fields; n != nil; n = n.prev {
+		nodes = append(nodes, n)
+		size += len(n.fields)
+	}
+	fds := make([]zap.Field, 0, size)
+	group := 0
+	for i := len(nodes) - 1; i >= 0; i-- {
+		for ; group < nodes[i].group; group++ {
+			fds = append(fds, zap.Namespace(l.groups[group]))
+		}
+		fds = l.appendZapFields(fds, nodes[i], nodes[:i], eval)
+	}
+	for ; group < len(l.groups); group++ {
+		fds = append(fds, zap.Namespace(l.groups[group]))
+	}
+	return fds
+}
+
+// appendZapFields appends the fields of n to fds, skipping the ones overridden by the later nodes of the same group.
+// later holds the nodes following n, the last one first.
+func (l *zapLogger) appendZapFields(fds []zap.Field, n *fieldNode, later []*fieldNode, eval bool) []zap.Field {
+	for _, f := range n.fields {
+		if overridden(f.Key, n.group, later) {
+			continue
+		}
+		switch f.typ {
+		case stringField:
+			fds = append(fds, zap.String(f.Key, f.str))
+		case intField, int64Field:
+			fds = append(fds, zap.Int64(f.Key, f.num))
+		case float64Field:
+			fds = append(fds, zap.Float64(f.Key, math.Float64frombits(uint64(f.num))))
+		case boolField:
+			fds = append(fds, zap.Bool(f.Key, f.num != 0))
+		case durationField:
+			fds = append(fds, zap.Duration(f.Key, time.Duration(f.num)))
+		case byteSizeField:
+			fds = append(fds, zap.Any(f.Key, byteSizeValue(ByteSize(f.num), l.humanizeBytes)))
+		default:
+			v := f.iface
+			if lv, ok := v.(LazyValue); ok {
+				if !eval {
+					continue
+				}
+				v = lv.Value()
+			}
+			if b, ok := v.(ByteSize); ok {
+				v = byteSizeValue(b, l.humanizeBytes)
+			}
+			fds = append(fds, zap.Any(f.Key, v))
+		}
+	}
+	return fds
+}
+
+// overridden reports whether key is set by one of the later nodes of group.
+func overridden(key string, group int, later []*fieldNode) bool {
+	for i := len(later) - 1; i >= 0 && later[i].group == group; i-- {
+		if later[i].has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sugared returns the logger to write an entry at lvl with, carrying the evaluated lazy fields when lvl is enabled.
+func (l *zapLogger) sugared(lvl zapcore.Level) *zap.SugaredLogger {
+	if !l.hasLazy || !l.core.Enabled(lvl) {
+		return l.sugaredLogger
+	}
+	return l.base.With(l.zapFields(true)...).Sugar()
+}
+
+// desugared is like sugared for the methods logging msg as is, which need no formatting.
+func (l *zapLogger) desugared(lvl zapcore.Level) *zap.Logger {
+	if !l.hasLazy || !l.core.Enabled(lvl) {
+		return l.logger
+	}
+	return l.base.With(l.zapFields(true)...)
+}
+
+// WithAttrs adds fields in order, without going through a Fields map, so that their values are not boxed.
+// A key repeated in fields keeps its last value.
+func (l *zapLogger) WithAttrs(fields ...Field) Logger {
+	own := make([]Field, 0, len(fields))
+	for i, f := range fields {
+		if !hasField(fields[i+1:], f.Key) {
+			own = append(own, f)
+		}
+	}
+	return l.with(own)
 }
 
 func (l *zapLogger) GetDelegate() interface{} {
@@ -158,3 +461,9 @@ func GetDesugaredZapLoggerDelegate(instance Logger) (*zap.Logger, error) {
 		return nil, fmt.Errorf("expected zap.SugaredLogger but got: %v", v)
 	}
 }
+
+// ZapDelegate returns the zap logger behind l, false when l is not backed by zap.
+func ZapDelegate(l Logger) (*zap.SugaredLogger, bool) {
+	sugaredLogger, ok := l.GetDelegate().(*zap.SugaredLogger)
+	return sugaredLogger, ok
+}
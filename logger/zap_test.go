@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newDiscardZapLogger(b *testing.B) Logger {
+	b.Helper()
+	l, err := NewLogger(Configuration{
+		EnableConsole:     true,
+		ConsoleJSONFormat: true,
+		ConsoleLevel:      infoLvl,
+		ConsoleWriter:     io.Discard,
+	}, LoggerBackendZap)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return l
+}
+
+func manyFields(n int) Fields {
+	fields := make(Fields, n)
+	for i := 0; i < n; i++ {
+		fields["key"+strconv.Itoa(i)] = i
+	}
+	return fields
+}
+
+// BenchmarkZapWithFields adds the same small map to a logger already carrying 20 fields, as a middleware does
+// on every request. Its cost must not grow with the number of parent fields.
+func BenchmarkZapWithFields(b *testing.B) {
+	l := newDiscardZapLogger(b).WithFields(manyFields(20))
+	fields := Fields{"grpc.method": "/svc/Method", "request_id": "id"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(fields)
+	}
+}
+
+// BenchmarkZapWithFieldsOverride overrides a parent key, which re-applies every field.
+func BenchmarkZapWithFieldsOverride(b *testing.B) {
+	l := newDiscardZapLogger(b).WithFields(manyFields(20))
+	fields := Fields{"key0": "overridden"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(fields)
+	}
+}
+
+// BenchmarkZapWithFieldsChained chains 10 WithFields calls of distinct keys, its cost must be linear.
+func BenchmarkZapWithFieldsChained(b *testing.B) {
+	l := newDiscardZapLogger(b)
+	fields := make([]Fields, 10)
+	for i := range fields {
+		fields[i] = Fields{"key" + strconv.Itoa(i): i}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		child := l
+		for _, f := range fields {
+			child = child.WithFields(f)
+		}
+	}
+}
+
+func TestZapIncrementalFieldsMatchRebuild(t *testing.T) {
+	l, buf := newBufferLogger(t, LoggerBackendZap, Configuration{})
+	incremental := l.WithFields(Fields{"a": 1, "b": 2}).WithGroup("g").WithFields(Fields{"c": 3}).WithFields(Fields{"d": 4})
+	// Overriding c with the same value re-applies every field from scratch.
+	rebuilt := incremental.WithFields(Fields{"c": 3})
+
+	want := lastEntry(t, incremental, buf)
+	got := lastEntry(t, rebuilt, buf)
+	for _, entry := range []map[string]interface{}{want, got} {
+		delete(entry, "ts")
+		delete(entry, "caller")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("rebuilt entry %v, want %v", got, want)
+	}
+}
+
+func TestZapLazyAndByteSizeFields(t *testing.T) {
+	l, buf := newBufferLogger(t, LoggerBackendZap, Configuration{ConsoleLevel: infoLvl, HumanizeBytes: true})
+	calls := 0
+	lazy := Lazy(func() interface{} {
+		calls++
+		return "computed"
+	})
+	child := l.WithFields(Fields{"lazy": lazy}).WithFields(Fields{"size": ByteSize(1536)}).WithGroup("g").WithFields(Fields{"k": 1})
+
+	child.Debug("disabled")
+	if calls != 0 {
+		t.Fatalf("lazy value evaluated %d times for a disabled level", calls)
+	}
+	entry := lastEntry(t, child, buf)
+	if calls != 1 {
+		t.Fatalf("lazy value evaluated %d times, want 1", calls)
+	}
+	assertFields(t, entry, map[string]interface{}{"lazy": "computed", "size": "1.5 KiB"})
+	if group, _ := entry["g"].(map[string]interface{}); group["k"] != float64(1) {
+		t.Fatalf("group g = %v, want k=1", entry["g"])
+	}
+}
+
+func TestZapIndependentConsoleAndFileLevels(t *testing.T) {
+	var console, file bytes.Buffer
+	l, err := NewLogger(Configuration{
+		EnableConsole:  true,
+		ConsoleLevel:   infoLvl,
+		ConsoleWriter:  &console,
+		EnableFile:     true,
+		FileLevel:      debugLvl,
+		FileJSONFormat: true,
+		FileWriter:     &file,
+	}, LoggerBackendZap)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	l.Debug("debug line")
+	l.Info("info line")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if strings.Contains(console.String(), "debug line") {
+		t.Errorf("console output %q contains the debug entry", console.String())
+	}
+	if !strings.Contains(console.String(), "info line") {
+		t.Errorf("console output %q does not contain the info entry", console.String())
+	}
+	for _, msg := range []string{"debug line", "info line"} {
+		if !strings.Contains(file.String(), msg) {
+			t.Errorf("file output %q does not contain %q", file.String(), msg)
+		}
+	}
+}